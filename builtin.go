@@ -0,0 +1,241 @@
+package hai
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/danbrakeley/hai/internal/object"
+)
+
+// Builtin is a function implemented in Go that Hai programs can call. It
+// returns nil for null, or the result of Errorf to fail with a runtime error.
+type Builtin func(args []Object) Object
+
+// Errorf returns a runtime error, for a Builtin to return.
+func Errorf(format string, a ...any) Object {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+// Register makes fn callable from Hai as a global named name. If fn panics,
+// the program fails with a runtime error rather than the panic reaching the
+// caller of Run.
+func (in *Interpreter) Register(name string, fn Builtin) error {
+	if fn == nil {
+		return fmt.Errorf("hai: builtin %s is nil", name)
+	}
+	return in.define(name, func(args ...Object) Object {
+		return fn(args)
+	})
+}
+
+// RegisterFunc makes any Go function callable from Hai as a global named
+// name, converting between Go and Hai values as it is called.
+//
+// Parameters may be any integer or float type, *big.Int, string, bool, Object
+// (given the Hai value as it is), or any (given the result of ToGo), and the
+// last one may be variadic. Float parameters also accept integers. Arguments
+// of the wrong type, integers that do not fit, and the wrong number of
+// arguments are all runtime errors.
+//
+// fn may return nothing, one value, an error, or one value and an error. The
+// value is converted by FromGo, and a non-nil error fails the program with a
+// runtime error that has the error's message.
+func (in *Interpreter) RegisterFunc(name string, fn any) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("hai: cannot register %T as a function", fn)
+	}
+	f, err := newReflectFunc(name, v)
+	if err != nil {
+		return err
+	}
+	return in.define(name, f.call)
+}
+
+func (in *Interpreter) define(name string, fn object.BuiltinFunction) error {
+	if !isIdentifier(name) {
+		return fmt.Errorf("hai: %q is not a valid name", name)
+	}
+	in.env.Set(name, &object.Builtin{
+		Name: name,
+		Fn: func(args ...Object) (result Object) {
+			defer func() {
+				if r := recover(); r != nil {
+					result = Errorf("%s panicked: %v", name, r)
+				}
+			}()
+			return fn(args...)
+		},
+	})
+	return nil
+}
+
+var (
+	errorType  = reflect.TypeFor[error]()
+	bigIntType = reflect.TypeFor[*big.Int]()
+	objectType = reflect.TypeFor[Object]()
+	anyType    = reflect.TypeFor[any]()
+)
+
+// reflectFunc calls a Go function with Hai arguments.
+type reflectFunc struct {
+	name     string
+	fn       reflect.Value
+	params   []reflect.Type // the element type, for a variadic parameter
+	variadic bool
+	value    bool // fn returns a value
+	err      bool // fn returns an error, last
+}
+
+func newReflectFunc(name string, fn reflect.Value) (*reflectFunc, error) {
+	t := fn.Type()
+	f := &reflectFunc{name: name, fn: fn, variadic: t.IsVariadic()}
+
+	for i := 0; i < t.NumIn(); i++ {
+		p := t.In(i)
+		if f.variadic && i == t.NumIn()-1 {
+			p = p.Elem()
+		}
+		if !isParamType(p) {
+			return nil, fmt.Errorf("hai: %s: parameter %d has unsupported type %s", name, i+1, p)
+		}
+		f.params = append(f.params, p)
+	}
+
+	switch t.NumOut() {
+	case 0:
+	case 1:
+		f.err = t.Out(0) == errorType
+		f.value = !f.err
+	case 2:
+		if t.Out(1) != errorType {
+			return nil, fmt.Errorf("hai: %s: second result must be an error, not %s", name, t.Out(1))
+		}
+		f.value, f.err = true, true
+	default:
+		return nil, fmt.Errorf("hai: %s: too many results", name)
+	}
+	return f, nil
+}
+
+func isParamType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
+		return true
+	}
+	return t == objectType || t == anyType || t == bigIntType
+}
+
+func (f *reflectFunc) call(args ...Object) Object {
+	if err := f.checkArity(len(args)); err != nil {
+		return err
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		p := f.params[min(i, len(f.params)-1)]
+		v, err := toParam(arg, p)
+		if err != nil {
+			return Errorf("argument %d to `%s` %s", i+1, f.name, err)
+		}
+		in[i] = v
+	}
+
+	out := f.fn.Call(in)
+	if f.err {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return Errorf("%s", err)
+		}
+	}
+	if !f.value {
+		return nil
+	}
+	result, err := FromGo(out[0].Interface())
+	if err != nil {
+		return Errorf("result of `%s`: %s", f.name, err)
+	}
+	return result
+}
+
+func (f *reflectFunc) checkArity(n int) Object {
+	want := len(f.params)
+	if f.variadic {
+		if n < want-1 {
+			return Errorf("wrong number of arguments: want at least=%d, got=%d", want-1, n)
+		}
+		return nil
+	}
+	if n != want {
+		return Errorf("wrong number of arguments: want=%d, got=%d", want, n)
+	}
+	return nil
+}
+
+// toParam converts arg to a value of type t, or says what is wrong with it.
+func toParam(arg Object, t reflect.Type) (reflect.Value, error) {
+	switch {
+	case t == objectType:
+		return reflect.ValueOf(&arg).Elem(), nil
+	case t == anyType:
+		v := ToGo(arg)
+		return reflect.ValueOf(&v).Elem(), nil
+	case t == bigIntType:
+		i, ok := arg.(*object.Integer)
+		if !ok {
+			return reflect.Value{}, mismatch(object.INTEGER, arg)
+		}
+		return reflect.ValueOf(i.BigInt()), nil
+	}
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		s, ok := arg.(*object.String)
+		if !ok {
+			return v, mismatch(object.STRING, arg)
+		}
+		v.SetString(s.Value)
+	case reflect.Bool:
+		b, ok := arg.(*object.Boolean)
+		if !ok {
+			return v, mismatch(object.BOOLEAN, arg)
+		}
+		v.SetBool(b.Value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := arg.(*object.Integer)
+		if !ok {
+			return v, mismatch(object.INTEGER, arg)
+		}
+		if i.IsBig() || v.OverflowInt(i.Value) {
+			return v, fmt.Errorf("overflows %s: %s", t, i.Inspect())
+		}
+		v.SetInt(i.Value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, ok := arg.(*object.Integer)
+		if !ok {
+			return v, mismatch(object.INTEGER, arg)
+		}
+		switch {
+		case i.IsBig() && i.Sign() > 0 && i.Big.IsUint64() && !v.OverflowUint(i.Big.Uint64()):
+			v.SetUint(i.Big.Uint64())
+		case i.IsBig() || i.Value < 0 || v.OverflowUint(uint64(i.Value)):
+			return v, fmt.Errorf("overflows %s: %s", t, i.Inspect())
+		default:
+			v.SetUint(uint64(i.Value))
+		}
+	case reflect.Float32, reflect.Float64:
+		f, ok := object.AsFloat(arg)
+		if !ok {
+			return v, mismatch(object.FLOAT, arg)
+		}
+		v.SetFloat(f)
+	}
+	return v, nil
+}
+
+func mismatch(want object.ObjectType, got Object) error {
+	return fmt.Errorf("must be %s, got %s", want, got.Type())
+}
@@ -0,0 +1,150 @@
+package hai
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	in := New()
+	err := in.Register("sum", func(args []Object) Object {
+		var total int64
+		for i, arg := range args {
+			n, ok := ToGo(arg).(int64)
+			if !ok {
+				return Errorf("argument %d to `sum` must be an integer", i+1)
+			}
+			total += n
+		}
+		result, _ := FromGo(total)
+		return result
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Register("nothing", func([]Object) Object { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	expectEval(t, in, "sum(1, 2, 3)", int64(6))
+	expectEval(t, in, "sum()", int64(0))
+	expectEval(t, in, "nothing()", nil)
+	expectEvalError(t, in, `sum(1, "2")`, "argument 2 to `sum` must be an integer")
+}
+
+func TestRegisterFunc(t *testing.T) {
+	in := New()
+	funcs := map[string]any{
+		"add":    func(a, b int) int { return a + b },
+		"byte":   func(b uint8) uint8 { return b },
+		"u64":    func(u uint64) uint64 { return u },
+		"digits": func(b *big.Int) int { return len(b.String()) },
+		"square": func(b *big.Int) *big.Int { return b.Mul(b, b) },
+		"repeat": strings.Repeat,
+		"not":    func(b bool) bool { return !b },
+		"join":   func(sep string, parts ...string) string { return strings.Join(parts, sep) },
+		"typeOf": func(v any) string { return fmt.Sprintf("%T", v) },
+		"same":   func(o Object) Object { return o },
+		"fail": func(fail bool) (string, error) {
+			if fail {
+				return "", errors.New("it failed")
+			}
+			return "ok", nil
+		},
+		"check":   func() error { return errors.New("checked") },
+		"noop":    func() {},
+		"half":    func(f float64) float32 { return float32(f / 2) },
+		"complex": func() complex128 { return 1i },
+	}
+	for name, fn := range funcs {
+		if err := in.RegisterFunc(name, fn); err != nil {
+			t.Fatalf("RegisterFunc(%s): %s", name, err)
+		}
+	}
+
+	expectEval(t, in, "add(40, 2)", int64(42))
+	expectEval(t, in, "byte(255)", int64(255))
+	expectEval(t, in, `repeat("ab", 3)`, "ababab")
+	expectEval(t, in, "not(true)", false)
+	expectEval(t, in, `join(", ")`, "")
+	expectEval(t, in, `join(", ", "a", "b")`, "a, b")
+	expectEval(t, in, "typeOf(1)", "int64")
+	expectEval(t, in, "typeOf(if (false) { 1 })", "<nil>")
+	expectEval(t, in, `same("x")`, "x")
+	expectEval(t, in, "fail(false)", "ok")
+	expectEval(t, in, "noop()", nil)
+	expectEval(t, in, "u64(18446744073709551615) == 18446744073709551615", true)
+	expectEval(t, in, "digits(123)", int64(3))
+	expectEval(t, in, "digits(10000000000000000000000)", int64(23))
+	expectEval(t, in, "square(3)", int64(9))
+	expectEval(t, in, "square(4294967296) == 18446744073709551616", true)
+	expectEval(t, in, "half(3)", 1.5)
+	expectEval(t, in, "half(0.5)", 0.25)
+
+	expectEvalError(t, in, "add(1)", "wrong number of arguments: want=2, got=1")
+	expectEvalError(t, in, "add(1, 2, 3)", "wrong number of arguments: want=2, got=3")
+	expectEvalError(t, in, "join()", "wrong number of arguments: want at least=1, got=0")
+	expectEvalError(t, in, `add(1, "2")`, "argument 2 to `add` must be integer, got string")
+	expectEvalError(t, in, `join(",", "a", 1)`, "argument 3 to `join` must be string, got integer")
+	expectEvalError(t, in, "not(1)", "argument 1 to `not` must be boolean, got integer")
+	expectEvalError(t, in, "byte(256)", "argument 1 to `byte` overflows uint8: 256")
+	expectEvalError(t, in, "byte(-1)", "argument 1 to `byte` overflows uint8: -1")
+	expectEvalError(t, in, "add(9223372036854775808, 1)", "argument 1 to `add` overflows int: 9223372036854775808")
+	expectEvalError(t, in, "u64(18446744073709551616)", "argument 1 to `u64` overflows uint64: 18446744073709551616")
+	expectEvalError(t, in, "digits(1.5)", "argument 1 to `digits` must be integer, got float")
+	expectEvalError(t, in, "fail(true)", "it failed")
+	expectEvalError(t, in, "check()", "checked")
+	expectEvalError(t, in, "complex()", "result of `complex`: hai: cannot convert complex128 to a Hai value")
+	expectEvalError(t, in, `half("1")`, "argument 1 to `half` must be float, got string")
+	expectEvalError(t, in, `repeat("a", -1)`, "repeat panicked: strings: negative Repeat count")
+}
+
+func TestRegisterFuncErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		fn       any
+		expected string
+	}{
+		{"f", 1, "hai: cannot register int as a function"},
+		{"f", (func())(nil), "hai: cannot register func() as a function"},
+		{"f", func(complex64) {}, "hai: f: parameter 1 has unsupported type complex64"},
+		{"f", func(int, ...[]int) {}, "hai: f: parameter 2 has unsupported type []int"},
+		{"f", func() (int, int) { return 0, 0 }, "hai: f: second result must be an error, not int"},
+		{"f", func() (int, int, error) { return 0, 0, nil }, "hai: f: too many results"},
+		{"if", func() {}, `hai: "if" is not a valid name`},
+	}
+	for _, tc := range cases {
+		err := New().RegisterFunc(tc.name, tc.fn)
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("RegisterFunc(%q, %T): expected %q, got %v", tc.name, tc.fn, tc.expected, err)
+		}
+	}
+}
+
+func expectEval(t *testing.T, in *Interpreter, input string, expected any) {
+	t.Helper()
+	actual, err := in.Eval(input)
+	if err != nil {
+		t.Errorf("%s: unexpected error: %s", input, err)
+		return
+	}
+	if actual != expected {
+		t.Errorf("%s: expected %#v, got %#v", input, expected, actual)
+	}
+}
+
+func expectEvalError(t *testing.T, in *Interpreter, input string, expected string) {
+	t.Helper()
+	_, err := in.Eval(input)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Errorf("%s: expected a *RuntimeError, got %#v", input, err)
+		return
+	}
+	if runtimeErr.Message != expected {
+		t.Errorf("%s: expected %q, got %q", input, expected, runtimeErr.Message)
+	}
+}
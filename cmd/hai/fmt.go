@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/danbrakeley/hai/internal/diff"
+	"github.com/danbrakeley/hai/internal/format"
+	"github.com/danbrakeley/hai/internal/highlight"
+)
+
+const fmtUsage = `usage: hai fmt [-w] [-d] [files...]
+
+Reformats Hai source in the canonical style, keeping comments, and prints the
+result. With no files, or a file named "-", stdin is formatted.
+
+flags:
+`
+
+// runFmt runs the fmt subcommand, with args being everything after "fmt".
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("hai fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, fmtUsage)
+		flags.PrintDefaults()
+	}
+	write := flags.Bool("w", false, "write the result back to each file, instead of printing it")
+	check := flags.Bool("d", false, "print a diff for each file that is not formatted, instead of the result,\nand exit with status 1 if there were any")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	f := formatter{write: *write, check: *check, stdout: stdout, stderr: stderr}
+	filenames := flags.Args()
+	if len(filenames) == 0 {
+		filenames = []string{"-"}
+	}
+	if f.write && slices.Contains(filenames, "-") {
+		fmt.Fprintln(stderr, "hai fmt: cannot use -w when formatting stdin")
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, filename := range filenames {
+		filename, src, err := readSource(filename, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "hai fmt: %s\n", err)
+			code = max(code, exitNoInput)
+			continue
+		}
+		code = max(code, f.format(filename, string(src)))
+	}
+	return code
+}
+
+type formatter struct {
+	write  bool // replace the file with the result
+	check  bool // print a diff instead of the result
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// format formats the source of one file, and returns the exit code for it.
+func (f formatter) format(filename, src string) int {
+	out, diags := format.Source(filename, src)
+	if len(diags) > 0 {
+		highlight.Renderer(highlight.Enabled(f.stderr)).Render(f.stderr, src, diags...)
+		return exitParseError
+	}
+
+	code := exitOK
+	if f.check {
+		if d := diff.Unified(filename+".orig", filename, src, out); d != "" {
+			fmt.Fprint(f.stdout, d)
+			code = exitUnformatted
+		}
+	}
+	if f.write && out != src {
+		info, err := os.Stat(filename)
+		if err == nil {
+			err = os.WriteFile(filename, []byte(out), info.Mode().Perm())
+		}
+		if err != nil {
+			fmt.Fprintf(f.stderr, "hai fmt: %s\n", err)
+			return exitCantCreate
+		}
+	}
+	if !f.check && !f.write {
+		fmt.Fprint(f.stdout, out)
+	}
+	return code
+}
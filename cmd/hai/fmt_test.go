@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFmt(t *testing.T) {
+	const (
+		messy     = "let x=1;\nputs( x )\n"
+		formatted = "let x = 1;\nputs(x);\n"
+	)
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	good := write("good.hai", formatted)
+	bad := write("bad.hai", "let x = ;\n")
+
+	cases := []struct {
+		name     string
+		args     []string
+		stdin    string
+		code     int
+		stdout   string
+		inStderr string
+	}{
+		{"stdin", []string{"fmt"}, messy, exitOK, formatted, ""},
+		{"file", []string{"fmt", write("print.hai", messy)}, "", exitOK, formatted, ""},
+		{"check formatted", []string{"fmt", "-d", good}, "", exitOK, "", ""},
+		{"check stdin", []string{"fmt", "-d"}, messy, exitUnformatted,
+			"--- <stdin>.orig\n+++ <stdin>\n@@ -1,2 +1,2 @@\n-let x=1;\n-puts( x )\n+let x = 1;\n+puts(x);\n", ""},
+		{"parse error", []string{"fmt", bad, good}, "", exitParseError, formatted, "bad.hai:1:9"},
+		{"missing file", []string{"fmt", filepath.Join(dir, "missing.hai")}, "", exitNoInput, "", "missing.hai"},
+		{"dash", []string{"fmt", "-"}, messy, exitOK, formatted, ""},
+		{"write stdin", []string{"fmt", "-w"}, messy, exitUsage, "", "cannot use -w"},
+		{"write dash", []string{"fmt", "-w", good, "-"}, messy, exitUsage, "", "cannot use -w"},
+		{"help", []string{"fmt", "-h"}, "", exitOK, "", "usage: hai fmt"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+			if code != tc.code {
+				t.Errorf("expected exit code %d, got %d (stderr: %s)", tc.code, code, stderr.String())
+			}
+			if stdout.String() != tc.stdout {
+				t.Errorf("expected stdout %q, got %q", tc.stdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tc.inStderr) {
+				t.Errorf("expected stderr to contain %q, got %q", tc.inStderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestFmtWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messy.hai")
+	if err := os.WriteFile(path, []byte("let x=1;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"fmt", "-w", "-d", path}, nil, &stdout, &stderr); code != exitUnformatted {
+		t.Fatalf("expected exit code %d, got %d (stderr: %s)", exitUnformatted, code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "+let x = 1;") {
+		t.Errorf("expected a diff, got %q", stdout.String())
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(src) != "let x = 1;\n" {
+		t.Errorf("expected the file to be formatted, got %q", src)
+	}
+
+	// now there is nothing left to change
+	stdout.Reset()
+	if code := run([]string{"fmt", "-d", path}, nil, &stdout, &stderr); code != exitOK || stdout.Len() > 0 {
+		t.Errorf("expected no changes, got exit code %d and %q", code, stdout.String())
+	}
+}
@@ -1,14 +1,203 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"github.com/danbrakeley/hai/internal/buildvar"
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/engine"
+	"github.com/danbrakeley/hai/internal/highlight"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/parser"
 	"github.com/danbrakeley/hai/internal/repl"
 )
 
+// Exit codes. Where one applies, these follow the BSD sysexits.h conventions.
+const (
+	exitOK           = 0
+	exitRuntimeError = 1  // the program failed while running
+	exitUnformatted  = 1  // hai fmt -d found source that was not formatted
+	exitUsage        = 64 // the command line was invalid
+	exitParseError   = 65 // the program could not be parsed or compiled
+	exitNoInput      = 66 // the program could not be read
+	exitCantCreate   = 73 // an output file could not be written
+)
+
+const usage = `usage:
+  hai [flags]                        start the REPL, or run the program piped to stdin
+  hai [flags] run <file> [args...]   run a Hai program ("-" reads it from stdin)
+  hai [flags] <file> [args...]       same as run
+  hai [flags] -e <expr> [args...]    evaluate expr and print the result
+  hai fmt [-w] [-d] [files...]       format Hai source (see hai fmt -h)
+  hai tokens [--json] [file]         print the tokens of a Hai program
+  hai ast [--json] [file]            print the syntax tree of a Hai program
+  hai version                        print version and build information
+
+The running program can read its arguments with argc and argv(i), where
+argv(0) is the name of the program.
+
+flags:
+`
+
 func main() {
-	fmt.Println("This is the Hai programming language!")
-	fmt.Println("Feel free to type in commands")
-	repl.Start(os.Stdin, os.Stdout)
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("hai", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	expr := flags.String("e", "", "evaluate `expr` and print its result")
+	version := flags.Bool("version", false, "print version and build information")
+	engineName := flags.String("engine", "vm", "`name` of the engine to run programs on ("+strings.Join(engine.Names, " or ")+")")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	eng, err := engine.New(*engineName, engine.WithOutput(stdout))
+	if err != nil {
+		fmt.Fprintf(stderr, "hai: %s\n", err)
+		return exitUsage
+	}
+
+	args = flags.Args()
+	switch {
+	case *version, len(args) == 1 && args[0] == "version":
+		fmt.Fprint(stdout, buildvar.Get())
+		return exitOK
+	case len(args) > 0 && args[0] == "fmt":
+		return runFmt(args[1:], stdin, stdout, stderr)
+	case len(args) > 0 && (args[0] == "tokens" || args[0] == "ast"):
+		return runSyntax(args[0], args[1:], stdin, stdout, stderr)
+	case isFlagSet(flags, "e"):
+		return execute(eng, "-e", *expr, args, stdout, stderr)
+	case len(args) > 0 && args[0] == "run":
+		if len(args) < 2 {
+			fmt.Fprintln(stderr, "hai: run needs the name of a file to run")
+			flags.Usage()
+			return exitUsage
+		}
+		return runFile(eng, args[1], args[2:], stdin, stdout, stderr)
+	case len(args) > 0:
+		return runFile(eng, args[0], args[1:], stdin, stdout, stderr)
+	case isTerminal(stdin):
+		fmt.Fprintf(stdout, "This is the Hai programming language! %s\n", buildvar.Get().Summary())
+		fmt.Fprintln(stdout, "Feel free to type in commands")
+		if err := repl.Start(stdin, stdout, repl.WithEngine(*engineName)); err != nil {
+			fmt.Fprintf(stderr, "hai: %s\n", err)
+			return exitNoInput
+		}
+		return exitOK
+	default:
+		return runFile(eng, "-", nil, stdin, stdout, stderr)
+	}
+}
+
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	found := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
+// isTerminal reports if r is an interactive terminal, as opposed to a pipe or
+// a redirected file.
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runFile runs the program in the named file, or on stdin if the name is "-".
+func runFile(eng engine.Engine, filename string, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	filename, src, err := readSource(filename, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "hai: %s\n", err)
+		return exitNoInput
+	}
+
+	// unlike -e, the result of a whole program is not printed
+	return execute(eng, filename, src, args, io.Discard, stderr)
+}
+
+// readSource reads the named file, or stdin if the name is "-". It returns
+// the name to use for the file in messages.
+func readSource(filename string, stdin io.Reader) (string, string, error) {
+	var src []byte
+	var err error
+	if filename == "-" {
+		src, err = io.ReadAll(stdin)
+		filename = "<stdin>"
+	} else {
+		src, err = os.ReadFile(filename)
+	}
+	return filename, string(src), err
+}
+
+// execute runs src, and prints its result to out (if it has one).
+func execute(eng engine.Engine, filename, src string, args []string, out, stderr io.Writer) int {
+	p := parser.New(lexer.New(src, lexer.WithFilename(filename)))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); len(diags) > 0 {
+		highlight.Renderer(highlight.Enabled(stderr)).Render(stderr, src, diags...)
+		return exitParseError
+	}
+
+	defineArgs(eng, append([]string{filename}, args...))
+
+	result, err := eng.Run(program)
+	if err != nil {
+		var d diag.Diagnostic
+		if errors.As(err, &d) {
+			highlight.Renderer(highlight.Enabled(stderr)).Render(stderr, src, d)
+			return exitParseError
+		}
+		fmt.Fprintf(stderr, "%s: runtime error: %s\n", filename, err)
+		return exitRuntimeError
+	}
+
+	if result != nil && result.Type() != object.NULL {
+		fmt.Fprintln(out, result.Inspect())
+	}
+	return exitOK
+}
+
+// defineArgs makes the program's arguments available as argc and argv(i).
+func defineArgs(eng engine.Engine, argv []string) {
+	eng.Define("argc", &object.Integer{Value: int64(len(argv))})
+	eng.Define("argv", &object.Builtin{
+		Name: "argv",
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return &object.Error{Message: fmt.Sprintf("wrong number of arguments: want=1, got=%d", len(args))}
+			}
+			i, ok := args[0].(*object.Integer)
+			if !ok {
+				return &object.Error{Message: fmt.Sprintf("argument to `argv` not supported, got %s", args[0].Type())}
+			}
+			if i.IsBig() || i.Value < 0 || i.Value >= int64(len(argv)) {
+				return &object.Error{Message: fmt.Sprintf("argv index out of range: %s", i.Inspect())}
+			}
+			return &object.String{Value: argv[i.Value]}
+		},
+	})
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/buildvar"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	ok := write("ok.hai", "#!/usr/bin/env hai\nlet x = argc;\n")
+	bad := write("bad.hai", "let x = ;\n")
+	undefined := write("undefined.hai", "x;\n")
+	crash := write("crash.hai", "1 / 0;\n")
+	hello := write("hello.hai", "puts(\"hello\");\n")
+
+	cases := []struct {
+		name     string
+		args     []string
+		stdin    string
+		code     int
+		stdout   string
+		inStderr string
+	}{
+		{"eval", []string{"-e", "1 + 2"}, "", exitOK, "3\n", ""},
+		{"eval null", []string{"-e", "if (false) { 1 }"}, "", exitOK, "", ""},
+		{"eval args", []string{"-e", "argv(argc - 1)", "a", "b"}, "", exitOK, "b\n", ""},
+		{"eval on evaluator", []string{"-engine", "eval", "-e", "argc"}, "", exitOK, "1\n", ""},
+		{"eval puts", []string{"-e", "puts(1)"}, "", exitOK, "1\n", ""},
+		{"eval parse error", []string{"-e", "1 +"}, "", exitParseError, "", "error[E0202]"},
+		{"eval runtime error", []string{"-e", "argv(5)"}, "", exitRuntimeError, "", "-e: runtime error: argv index out of range: 5"},
+		{"run", []string{"run", ok, "arg"}, "", exitOK, "", ""},
+		{"run without run", []string{ok}, "", exitOK, "", ""},
+		{"run puts", []string{"run", hello}, "", exitOK, "hello\n", ""},
+		{"run puts on evaluator", []string{"-engine", "eval", "run", hello}, "", exitOK, "hello\n", ""},
+		{"run parse error", []string{"run", bad}, "", exitParseError, "", "bad.hai:1:9"},
+		{"run compile error", []string{"run", undefined}, "", exitParseError, "", "error[E0301]"},
+		{"run compile error on evaluator", []string{"-engine", "eval", "run", undefined}, "", exitParseError, "", "error[E0301]"},
+		{"run runtime error", []string{"run", crash}, "", exitRuntimeError, "", "runtime error: division by zero"},
+		{"run missing file", []string{"run", filepath.Join(dir, "missing.hai")}, "", exitNoInput, "", "missing.hai"},
+		{"run without file", []string{"run"}, "", exitUsage, "", "usage:"},
+		{"run stdin", []string{"run", "-"}, "let a = 1;", exitOK, "", ""},
+		{"piped stdin", nil, "1 / 0;", exitRuntimeError, "", "<stdin>: runtime error"},
+		{"unknown flag", []string{"-x"}, "", exitUsage, "", "usage:"},
+		{"unknown engine", []string{"-engine", "jit", "-e", "1"}, "", exitUsage, "", "unknown engine"},
+		{"version", []string{"version"}, "", exitOK, buildvar.Get().String(), ""},
+		{"version flag", []string{"--version"}, "", exitOK, buildvar.Get().String(), ""},
+		{"help", []string{"-h"}, "", exitOK, "", "usage:"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+			if code != tc.code {
+				t.Errorf("expected exit code %d, got %d (stderr: %s)", tc.code, code, stderr.String())
+			}
+			if stdout.String() != tc.stdout {
+				t.Errorf("expected stdout %q, got %q", tc.stdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tc.inStderr) {
+				t.Errorf("expected stderr to contain %q, got %q", tc.inStderr, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/highlight"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/parser"
+	"github.com/danbrakeley/hai/internal/syntaxjson"
+)
+
+const syntaxUsage = `usage: hai %[1]s [--json] [file]
+
+Prints the %[2]s of a Hai program, read from file, or from stdin if there is
+no file or it is "-". The --json output is described in the documentation of
+the internal/syntaxjson package.
+
+flags:
+`
+
+// runSyntax runs the tokens or ast subcommand, with args being everything
+// after the name of the subcommand.
+func runSyntax(command string, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	what := "tokens"
+	if command == "ast" {
+		what = "syntax tree"
+	}
+	flags := flag.NewFlagSet("hai "+command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, syntaxUsage, command, what)
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", false, "print the "+what+" as JSON")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintf(stderr, "hai %s: too many arguments\n", command)
+		flags.Usage()
+		return exitUsage
+	}
+
+	filename := "-"
+	if flags.NArg() == 1 {
+		filename = flags.Arg(0)
+	}
+	filename, src, err := readSource(filename, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "hai %s: %s\n", command, err)
+		return exitNoInput
+	}
+
+	var doc any
+	var diags []diag.Diagnostic
+	if command == "tokens" {
+		tokens := syntaxjson.Lex(filename, src)
+		doc, diags = tokens, tokens.Diagnostics
+		if !*asJSON {
+			for _, tok := range tokens.Tokens {
+				fmt.Fprintf(stdout, "%-7s %-10s %s\n", tok.Pos(), tok.Type(), tok.Literal())
+			}
+		}
+	} else if *asJSON {
+		tree := syntaxjson.Parse(filename, src)
+		doc, diags = tree, tree.Diagnostics
+	} else {
+		p := parser.New(lexer.New(src, lexer.WithFilename(filename)))
+		program := p.ParseProgram()
+		if diags = p.Diagnostics(); len(diags) == 0 {
+			ast.Fprint(stdout, program)
+		}
+	}
+
+	if *asJSON {
+		// problems are part of the document, rather than printed separately
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(doc); err != nil {
+			fmt.Fprintf(stderr, "hai %s: %s\n", command, err)
+			return exitCantCreate
+		}
+	} else if len(diags) > 0 {
+		highlight.Renderer(highlight.Enabled(stderr)).Render(stderr, src, diags...)
+	}
+
+	if diag.HasErrors(diags) {
+		return exitParseError
+	}
+	return exitOK
+}
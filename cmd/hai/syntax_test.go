@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/syntaxjson"
+)
+
+func TestSyntax(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		stdin    string
+		code     int
+		stdout   string
+		inStderr string
+	}{
+		{"tokens", []string{"tokens"}, "x; // c", exitOK, "1:1     ident      x\n1:2     semicolon  ;\n1:4     comment    // c\n", ""},
+		{"tokens error", []string{"tokens", "-"}, "1 @", exitParseError, "1:1     int        1\n1:3     illegal    @\n", "error[E0101]"},
+		{"ast", []string{"ast"}, "-x", exitOK, "Program\n  ExpressionStatement (1:1)\n    PrefixExpression - (1:1)\n      Identifier x (1:2)\n", ""},
+		{"ast error", []string{"ast"}, "let = 1;", exitParseError, "", "error[E0201]"},
+		{"too many files", []string{"ast", "a", "b"}, "", exitUsage, "", "too many arguments"},
+		{"help", []string{"tokens", "-h"}, "", exitOK, "", "usage: hai tokens"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+			if code != tc.code {
+				t.Errorf("expected exit code %d, got %d (stderr: %s)", tc.code, code, stderr.String())
+			}
+			if stdout.String() != tc.stdout {
+				t.Errorf("expected stdout %q, got %q", tc.stdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tc.inStderr) {
+				t.Errorf("expected stderr to contain %q, got %q", tc.inStderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestSyntaxJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"tokens", "--json"}, strings.NewReader("1 @"), &stdout, &stderr); code != exitParseError {
+		t.Errorf("expected exit code %d, got %d", exitParseError, code)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("expected problems to only be in the JSON, got %q", stderr.String())
+	}
+	tokens, err := syntaxjson.DecodeTokens(&stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens.Tokens) != 2 || len(tokens.Diagnostics) != 1 {
+		t.Errorf("expected 2 tokens and 1 diagnostic, got %+v", tokens)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"ast", "--json"}, strings.NewReader("f(1)"), &stdout, &stderr); code != exitOK {
+		t.Errorf("expected exit code %d, got %d", exitOK, code)
+	}
+	tree, err := syntaxjson.DecodeTree(&stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := tree.Root.AST()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if program.String() != "f(1);" {
+		t.Errorf("expected f(1);, got %s", program.String())
+	}
+	if tree.Root.Span.File != "<stdin>" {
+		t.Errorf("expected the file to be <stdin>, got %q", tree.Root.Span.File)
+	}
+}
@@ -0,0 +1,47 @@
+package hai_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danbrakeley/hai"
+)
+
+func Example() {
+	in := hai.New()
+	if err := in.SetGlobal("name", "world"); err != nil {
+		panic(err)
+	}
+
+	result, err := in.Eval(`let greet = fn(who) { "hello " + who }; greet(name)`)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(result)
+	// Output: hello world
+}
+
+func ExampleSyntaxError() {
+	_, err := hai.New().Compile("let x 1;")
+	fmt.Println(err)
+	// Output: hai: syntax error at 1:7: expected next token to be assign, got int instead
+}
+
+func ExampleInterpreter_RegisterFunc() {
+	in := hai.New()
+	err := in.RegisterFunc("shout", func(s string, times int) string {
+		return strings.Repeat(strings.ToUpper(s), times)
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := in.Eval(`shout("hai", 2)`)
+	fmt.Println(result, err)
+
+	_, err = in.Eval(`shout("hai")`)
+	fmt.Println(err)
+	// Output:
+	// HAIHAI <nil>
+	// hai: runtime error: wrong number of arguments: want=2, got=1
+}
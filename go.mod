@@ -5,6 +5,10 @@ go 1.22.5
 require (
 	github.com/danbrakeley/bsh v0.2.2
 	github.com/magefile/mage v1.15.0
+	golang.org/x/term v0.29.0
 )
 
-require github.com/danbrakeley/commandline v1.0.0 // indirect
+require (
+	github.com/danbrakeley/commandline v1.0.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
@@ -0,0 +1,207 @@
+// Package hai embeds the Hai programming language in Go programs.
+//
+// An Interpreter holds the global bindings that programs share. Source is
+// compiled into a Program, which can then be run any number of times:
+//
+//	in := hai.New()
+//	if err := in.SetGlobal("name", "world"); err != nil {
+//		return err
+//	}
+//	greeting, err := in.Eval(`"hello " + name`)
+//
+// Values are converted between Go and Hai as described by ToGo and FromGo.
+//
+// This package is the stable surface of Hai; the packages under internal may
+// change at any time.
+package hai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/evaluator"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/parser"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// Interpreter runs Hai programs. Globals bound by one program, or by
+// SetGlobal, are seen by the programs run after it. An Interpreter must not
+// be used by more than one goroutine at a time.
+type Interpreter struct {
+	env    *object.Environment
+	limits Limits
+	out    io.Writer
+}
+
+// Option configures an Interpreter.
+type Option func(*Interpreter)
+
+// WithOutput sets where programs write to with puts. The default is
+// os.Stdout.
+func WithOutput(w io.Writer) Option {
+	return func(in *Interpreter) {
+		in.out = w
+	}
+}
+
+// New returns an Interpreter with no globals other than the builtins.
+func New(opts ...Option) *Interpreter {
+	in := &Interpreter{env: object.NewEnvironment(), out: os.Stdout}
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
+
+// Program is Hai source that has been compiled, ready to be run.
+type Program struct {
+	program *ast.Program
+}
+
+// Compile parses source into a Program. If source has errors, they are
+// returned as a *SyntaxError.
+func (in *Interpreter) Compile(source string) (*Program, error) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); diag.HasErrors(diags) {
+		return nil, newSyntaxError(diags)
+	}
+	return &Program{program: program}, nil
+}
+
+// Run runs a compiled program, and returns the value of its last statement,
+// converted by ToGo. Problems found while running are returned as a
+// *RuntimeError. If the program goes over one of the Interpreter's Limits it
+// is stopped with a *LimitExceeded, and if ctx is done first it is stopped
+// with the cause.
+func (in *Interpreter) Run(ctx context.Context, program *Program) (any, error) {
+	if in.limits.Time > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, in.limits.Time,
+			&LimitExceeded{Limit: TimeLimit, Max: int64(in.limits.Time)})
+		defer cancel()
+	}
+
+	result, err := evaluator.EvalContext(ctx, program.program, in.env, in.limits.evaluator(), in.out)
+	if limitErr, ok := err.(*evaluator.LimitExceeded); ok {
+		return nil, &LimitExceeded{Limit: Limit(limitErr.Limit), Max: limitErr.Max}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err, ok := result.(*object.Error); ok {
+		return nil, &RuntimeError{Message: err.Message}
+	}
+	return ToGo(result), nil
+}
+
+// Eval compiles and runs source, and returns the value of its last
+// statement.
+func (in *Interpreter) Eval(source string) (any, error) {
+	program, err := in.Compile(source)
+	if err != nil {
+		return nil, err
+	}
+	return in.Run(context.Background(), program)
+}
+
+// SetGlobal binds name to value, converted by FromGo, for the programs run
+// after it.
+func (in *Interpreter) SetGlobal(name string, value any) error {
+	if !isIdentifier(name) {
+		return fmt.Errorf("hai: %q is not a valid name", name)
+	}
+	obj, err := FromGo(value)
+	if err != nil {
+		return err
+	}
+	in.env.Set(name, obj)
+	return nil
+}
+
+// Global returns the value bound to name, converted by ToGo, and whether
+// there is one.
+func (in *Interpreter) Global(name string) (any, bool) {
+	obj, ok := in.env.Get(name)
+	if !ok {
+		return nil, false
+	}
+	return ToGo(obj), true
+}
+
+// Globals returns the names of all the globals, in sorted order.
+func (in *Interpreter) Globals() []string {
+	return in.env.Names()
+}
+
+func isIdentifier(name string) bool {
+	l := lexer.New(name)
+	tok := l.NextToken()
+	return tok.Is(token.IDENT) && tok.Literal() == name && l.NextToken().Is(token.EOF)
+}
+
+// SyntaxError is returned by Compile when source cannot be parsed.
+type SyntaxError struct {
+	Problems []Problem
+}
+
+// Problem is one thing wrong with source.
+type Problem struct {
+	Line    int    // starting at 1
+	Column  int    // starting at 1, counted in runes
+	Code    string // identifies the kind of problem, ie "E0201"
+	Message string
+	Hint    string // a suggestion on how to fix it, if there is one
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+func newSyntaxError(diags []diag.Diagnostic) *SyntaxError {
+	e := &SyntaxError{}
+	for _, d := range diags {
+		if d.Severity != diag.Error {
+			continue
+		}
+		e.Problems = append(e.Problems, Problem{
+			Line:    d.Span.Start.Line,
+			Column:  d.Span.Start.Column,
+			Code:    string(d.Code),
+			Message: d.Message,
+			Hint:    d.Hint,
+		})
+	}
+	return e
+}
+
+// Error describes the first problem, and how many others there are.
+func (e *SyntaxError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("hai: syntax error at ")
+	sb.WriteString(e.Problems[0].String())
+	switch n := len(e.Problems) - 1; n {
+	case 0:
+	case 1:
+		sb.WriteString(" (and 1 more problem)")
+	default:
+		fmt.Fprintf(&sb, " (and %d more problems)", n)
+	}
+	return sb.String()
+}
+
+// RuntimeError is returned by Run when a program fails while running.
+type RuntimeError struct {
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return "hai: runtime error: " + e.Message
+}
@@ -0,0 +1,189 @@
+package hai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected any
+	}{
+		{"1 + 2", int64(3)},
+		{"1 + 0.5", 1.5},
+		{"9223372036854775807 - 9223372036854775808", int64(-1)},
+		{`"a" + "b"`, "ab"},
+		{"1 < 2", true},
+		{"if (false) { 1 }", nil},
+		{"", nil},
+		{"let a = 1;", nil},
+		{"let double = fn(x) { x * 2 }; double(4)", int64(8)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := New().Eval(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEvalKeepsGlobals(t *testing.T) {
+	in := New()
+	if _, err := in.Eval("let add = fn(a, b) { a + b }; let x = 2;"); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := in.Eval("add(x, 3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != int64(5) {
+		t.Errorf("expected 5, got %#v", actual)
+	}
+
+	if v, ok := in.Global("x"); !ok || v != int64(2) {
+		t.Errorf("expected x to be 2, got %#v, %t", v, ok)
+	}
+	if _, ok := in.Global("y"); ok {
+		t.Error("expected y to not be set")
+	}
+	if add, _ := in.Global("add"); add == nil {
+		t.Error("expected add to be set")
+	} else if _, ok := add.(Object); !ok {
+		t.Errorf("expected add to be an Object")
+	}
+}
+
+func TestSetGlobal(t *testing.T) {
+	in := New()
+	for name, value := range map[string]any{
+		"i": 40,
+		"u": uint8(2),
+		"s": "hai",
+		"b": true,
+		"n": nil,
+	} {
+		if err := in.SetGlobal(name, value); err != nil {
+			t.Fatalf("SetGlobal(%q): %s", name, err)
+		}
+	}
+
+	cases := []struct {
+		input    string
+		expected any
+	}{
+		{"i + u", int64(42)},
+		{"s", "hai"},
+		{"!b", false},
+		{"n", nil},
+	}
+	for _, tc := range cases {
+		actual, err := in.Eval(tc.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.input, err)
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: expected %#v, got %#v", tc.input, tc.expected, actual)
+		}
+	}
+}
+
+func TestSetGlobalErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		value any
+	}{
+		{"", 1},
+		{"two words", 1},
+		{"let", 1},
+		{"1x", 1},
+		{"x", 1i},
+		{"x", []int{1}},
+	}
+	for _, tc := range cases {
+		if err := New().SetGlobal(tc.name, tc.value); err == nil {
+			t.Errorf("SetGlobal(%q, %#v): expected an error", tc.name, tc.value)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	_, err := New().Compile("let = 1;\nlet x 2;")
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected a *SyntaxError, got %#v", err)
+	}
+	if len(syntaxErr.Problems) != 2 {
+		t.Fatalf("expected 2 problems, got %d: %v", len(syntaxErr.Problems), syntaxErr.Problems)
+	}
+	p := syntaxErr.Problems[1]
+	if p.Line != 2 || p.Column != 7 || p.Code == "" {
+		t.Errorf("unexpected second problem: %#v", p)
+	}
+	expected := "hai: syntax error at 1:5: " + syntaxErr.Problems[0].Message + " (and 1 more problem)"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	in := New()
+	program, err := in.Compile("1 + true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = in.Run(context.Background(), program)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected a *RuntimeError, got %#v", err)
+	}
+	if runtimeErr.Message != "type mismatch: integer + boolean" {
+		t.Errorf("unexpected message %q", runtimeErr.Message)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := in.Run(ctx, program); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %#v", err)
+	}
+}
+
+func TestProgramRunsMoreThanOnce(t *testing.T) {
+	in := New()
+	program, err := in.Compile("n + 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := in.SetGlobal("n", i); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := in.Run(context.Background(), program)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != int64(i+1) {
+			t.Errorf("expected %d, got %#v", i+1, actual)
+		}
+	}
+}
+
+func TestWithOutput(t *testing.T) {
+	var out strings.Builder
+	in := New(WithOutput(&out))
+	if _, err := in.Eval(`puts("hello", 1 + 2)`); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "hello\n3\n" {
+		t.Errorf("expected the output of puts, got %q", out.String())
+	}
+}
@@ -1,9 +1,21 @@
 package ast
 
-import "github.com/danbrakeley/hai/internal/token"
+import (
+	"math/big"
+	"strconv"
+	"strings"
 
+	"github.com/danbrakeley/hai/internal/numlit"
+	"github.com/danbrakeley/hai/internal/strlit"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// Node is implemented by every node in the tree. String returns the node as
+// source code, with every prefix and infix expression in parentheses, so that
+// parsing the result gives back the same tree.
 type Node interface {
 	TokenLiteral() string
+	String() string
 }
 
 type Statement interface {
@@ -28,6 +40,18 @@ func (p *Program) TokenLiteral() string {
 	}
 }
 
+func (p *Program) String() string {
+	return joinStatements(p.Statements)
+}
+
+func joinStatements(stmts []Statement) string {
+	strs := make([]string, 0, len(stmts))
+	for _, s := range stmts {
+		strs = append(strs, s.String())
+	}
+	return strings.Join(strs, " ")
+}
+
 type LetStatement struct {
 	Token token.Token
 	Name  *Identifier
@@ -36,6 +60,9 @@ type LetStatement struct {
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal() }
+func (ls *LetStatement) String() string {
+	return "let " + ls.Name.String() + " = " + ls.Value.String() + ";"
+}
 
 type Identifier struct {
 	Token token.Token
@@ -44,6 +71,7 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal() }
+func (i *Identifier) String() string       { return i.Value }
 
 type ReturnStatement struct {
 	Token       token.Token
@@ -52,3 +80,172 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal() }
+func (rs *ReturnStatement) String() string {
+	return "return " + rs.ReturnValue.String() + ";"
+}
+
+type ExpressionStatement struct {
+	Token      token.Token // the first token of the expression
+	Expression Expression
+}
+
+func (es *ExpressionStatement) statementNode()       {}
+func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal() }
+func (es *ExpressionStatement) String() string {
+	return es.Expression.String() + ";"
+}
+
+type IntegerLiteral struct {
+	Token token.Token
+	Value int64
+	Big   *big.Int // the value instead, if it does not fit in an int64
+}
+
+func (il *IntegerLiteral) expressionNode()      {}
+func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal() }
+func (il *IntegerLiteral) String() string {
+	if il.Big != nil {
+		return il.Big.String()
+	}
+	return strconv.FormatInt(il.Value, 10)
+}
+
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal() }
+func (fl *FloatLiteral) String() string       { return numlit.FormatFloat(fl.Value) }
+
+type StringLiteral struct {
+	Token token.Token // literal includes the quotes, and any escape sequences
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal() }
+func (sl *StringLiteral) String() string       { return strlit.Quote(sl.Value) }
+
+type Boolean struct {
+	Token token.Token
+	Value bool
+}
+
+func (b *Boolean) expressionNode()      {}
+func (b *Boolean) TokenLiteral() string { return b.Token.Literal() }
+func (b *Boolean) String() string       { return strconv.FormatBool(b.Value) }
+
+type PrefixExpression struct {
+	Token    token.Token // the prefix operator, ie ! or -
+	Operator string
+	Right    Expression
+}
+
+func (pe *PrefixExpression) expressionNode()      {}
+func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal() }
+func (pe *PrefixExpression) String() string {
+	return "(" + pe.Operator + pe.Right.String() + ")"
+}
+
+type InfixExpression struct {
+	Token    token.Token // the operator, ie + or ==
+	Left     Expression
+	Operator string
+	Right    Expression
+}
+
+func (ie *InfixExpression) expressionNode()      {}
+func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal() }
+func (ie *InfixExpression) String() string {
+	return "(" + ie.Left.String() + " " + ie.Operator + " " + ie.Right.String() + ")"
+}
+
+type BlockStatement struct {
+	Token      token.Token // the { token
+	Statements []Statement
+	Rbrace     token.Token // the } token
+}
+
+func (bs *BlockStatement) statementNode()       {}
+func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal() }
+func (bs *BlockStatement) String() string {
+	if len(bs.Statements) == 0 {
+		return "{ }"
+	}
+	return "{ " + joinStatements(bs.Statements) + " }"
+}
+
+type IfExpression struct {
+	Token       token.Token // the if token
+	Condition   Expression
+	Consequence *BlockStatement
+	Alternative *BlockStatement // nil if there is no else
+}
+
+func (ie *IfExpression) expressionNode()      {}
+func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal() }
+func (ie *IfExpression) String() string {
+	s := "if (" + ie.Condition.String() + ") " + ie.Consequence.String()
+	if ie.Alternative != nil {
+		s += " else " + ie.Alternative.String()
+	}
+	return s
+}
+
+type FunctionLiteral struct {
+	Token      token.Token // the fn token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (fl *FunctionLiteral) expressionNode()      {}
+func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal() }
+func (fl *FunctionLiteral) String() string {
+	params := make([]string, 0, len(fl.Parameters))
+	for _, p := range fl.Parameters {
+		params = append(params, p.String())
+	}
+	return "fn(" + strings.Join(params, ", ") + ") " + fl.Body.String()
+}
+
+type CallExpression struct {
+	Token     token.Token // the ( token
+	Function  Expression  // Identifier or FunctionLiteral
+	Arguments []Expression
+	Rparen    token.Token // the ) token
+}
+
+func (ce *CallExpression) expressionNode()      {}
+func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal() }
+func (ce *CallExpression) String() string {
+	args := make([]string, 0, len(ce.Arguments))
+	for _, a := range ce.Arguments {
+		args = append(args, a.String())
+	}
+	return ce.Function.String() + "(" + strings.Join(args, ", ") + ")"
+}
+
+// BadStatement stands in for a statement that could not be parsed. It covers
+// the tokens that the parser skipped over to recover from the error. Its
+// String is not valid source.
+type BadStatement struct {
+	Token token.Token // the first token of the statement
+	End   token.Pos   // just past the last token skipped
+}
+
+func (bs *BadStatement) statementNode()       {}
+func (bs *BadStatement) TokenLiteral() string { return bs.Token.Literal() }
+func (bs *BadStatement) String() string       { return "<bad statement>" }
+
+// BadExpression stands in for an expression that could not be parsed, in a
+// statement that otherwise could be. Its String is not valid source.
+type BadExpression struct {
+	Token token.Token // the first token of the expression
+	End   token.Pos   // just past the last token skipped
+}
+
+func (be *BadExpression) expressionNode()      {}
+func (be *BadExpression) TokenLiteral() string { return be.Token.Literal() }
+func (be *BadExpression) String() string       { return "<bad expression>" }
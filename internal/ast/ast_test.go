@@ -0,0 +1,33 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/parser"
+)
+
+func TestString(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1 + 2 * 3;", "let x = (1 + (2 * 3));"},
+		{"return -a;", "return (-a);"},
+		{`"tab\there" == "é"`, `("tab\there" == "é");`},
+		{"if (a < b) { a } else { b; true }", "if ((a < b)) { a; } else { b; true; };"},
+		{"let f = fn(x, y) {}; f(1, 2)(3)", "let f = fn(x, y) { }; f(1, 2)(3);"},
+		{"", ""},
+	}
+
+	for _, tc := range cases {
+		p := parser.New(lexer.New(tc.input))
+		program := p.ParseProgram()
+		if diags := p.Diagnostics(); len(diags) > 0 {
+			t.Fatalf("unexpected parse error in %q: %s", tc.input, diags[0].Error())
+		}
+		if s := program.String(); s != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.input, tc.expected, s)
+		}
+	}
+}
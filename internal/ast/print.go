@@ -0,0 +1,125 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/danbrakeley/hai/internal/numlit"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// Fprint writes an outline of the tree rooted at node to w, one node per
+// line, with children indented beneath their parent. It is meant for
+// debugging, so the format may change.
+func Fprint(w io.Writer, node Node) error {
+	p := &printer{w: w}
+	p.print("", node)
+	return p.err
+}
+
+type printer struct {
+	w      io.Writer
+	indent int
+	err    error
+}
+
+func (p *printer) line(label, kind, detail string, tok token.Token) {
+	if p.err != nil {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat("  ", p.indent))
+	if label != "" {
+		sb.WriteString(label)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(kind)
+	if detail != "" {
+		sb.WriteByte(' ')
+		sb.WriteString(detail)
+	}
+	if tok.Pos().IsValid() {
+		fmt.Fprintf(&sb, " (%s)", tok.Pos())
+	}
+	sb.WriteByte('\n')
+	_, p.err = io.WriteString(p.w, sb.String())
+}
+
+// print writes node and its children. label names the field of the parent
+// that holds node, if that is not obvious.
+func (p *printer) print(label string, node Node) {
+	switch node := node.(type) {
+	case *Program:
+		p.line(label, "Program", "", token.Token{})
+		for _, s := range node.Statements {
+			p.child("", s)
+		}
+	case *LetStatement:
+		p.line(label, "LetStatement", "", node.Token)
+		p.child("name", node.Name)
+		p.child("value", node.Value)
+	case *ReturnStatement:
+		p.line(label, "ReturnStatement", "", node.Token)
+		p.child("value", node.ReturnValue)
+	case *ExpressionStatement:
+		p.line(label, "ExpressionStatement", "", node.Token)
+		p.child("", node.Expression)
+	case *BlockStatement:
+		p.line(label, "BlockStatement", "", node.Token)
+		for _, s := range node.Statements {
+			p.child("", s)
+		}
+	case *Identifier:
+		p.line(label, "Identifier", node.Value, node.Token)
+	case *IntegerLiteral:
+		p.line(label, "IntegerLiteral", node.String(), node.Token)
+	case *FloatLiteral:
+		p.line(label, "FloatLiteral", numlit.FormatFloat(node.Value), node.Token)
+	case *StringLiteral:
+		p.line(label, "StringLiteral", strconv.Quote(node.Value), node.Token)
+	case *Boolean:
+		p.line(label, "Boolean", strconv.FormatBool(node.Value), node.Token)
+	case *PrefixExpression:
+		p.line(label, "PrefixExpression", node.Operator, node.Token)
+		p.child("", node.Right)
+	case *InfixExpression:
+		p.line(label, "InfixExpression", node.Operator, node.Token)
+		p.child("left", node.Left)
+		p.child("right", node.Right)
+	case *IfExpression:
+		p.line(label, "IfExpression", "", node.Token)
+		p.child("condition", node.Condition)
+		p.child("consequence", node.Consequence)
+		if node.Alternative != nil {
+			p.child("alternative", node.Alternative)
+		}
+	case *FunctionLiteral:
+		p.line(label, "FunctionLiteral", "", node.Token)
+		for _, param := range node.Parameters {
+			p.child("parameter", param)
+		}
+		p.child("body", node.Body)
+	case *CallExpression:
+		p.line(label, "CallExpression", "", node.Token)
+		p.child("function", node.Function)
+		for _, arg := range node.Arguments {
+			p.child("argument", arg)
+		}
+	case *BadStatement:
+		p.line(label, "BadStatement", "", node.Token)
+	case *BadExpression:
+		p.line(label, "BadExpression", "", node.Token)
+	case nil:
+		p.line(label, "<nil>", "", token.Token{})
+	default:
+		p.line(label, fmt.Sprintf("%T", node), "", token.Token{})
+	}
+}
+
+func (p *printer) child(label string, node Node) {
+	p.indent++
+	p.print(label, node)
+	p.indent--
+}
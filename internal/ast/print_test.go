@@ -0,0 +1,51 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/parser"
+)
+
+func TestFprint(t *testing.T) {
+	input := `let f = fn(x) { if (x) { -x } };
+f("a", 2 + 3);`
+
+	expected := `Program
+  LetStatement (1:1)
+    name: Identifier f (1:5)
+    value: FunctionLiteral (1:9)
+      parameter: Identifier x (1:12)
+      body: BlockStatement (1:15)
+        ExpressionStatement (1:17)
+          IfExpression (1:17)
+            condition: Identifier x (1:21)
+            consequence: BlockStatement (1:24)
+              ExpressionStatement (1:26)
+                PrefixExpression - (1:26)
+                  Identifier x (1:27)
+  ExpressionStatement (2:1)
+    CallExpression (2:2)
+      function: Identifier f (2:1)
+      argument: StringLiteral "a" (2:3)
+      argument: InfixExpression + (2:10)
+        left: IntegerLiteral 2 (2:8)
+        right: IntegerLiteral 3 (2:12)
+`
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); len(diags) > 0 {
+		t.Fatalf("unexpected parse error: %s", diags[0].Error())
+	}
+
+	var sb strings.Builder
+	if err := ast.Fprint(&sb, program); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
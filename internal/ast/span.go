@@ -0,0 +1,103 @@
+package ast
+
+import "github.com/danbrakeley/hai/internal/token"
+
+// Span returns the region of source that node was parsed from. It starts at
+// the first token of the node, and ends just past its last token, not counting
+// any closing parentheses around it or semicolon after it. Nodes that were not
+// parsed from source have an invalid span.
+func Span(node Node) token.Span {
+	start := startToken(node).Span()
+	return token.Span{File: start.File, Start: start.Start, End: end(node)}
+}
+
+func startToken(node Node) token.Token {
+	switch n := node.(type) {
+	case *Program:
+		if len(n.Statements) == 0 {
+			return token.Token{}
+		}
+		return startToken(n.Statements[0])
+	case *LetStatement:
+		return n.Token
+	case *ReturnStatement:
+		return n.Token
+	case *ExpressionStatement:
+		return n.Token
+	case *BlockStatement:
+		return n.Token
+	case *Identifier:
+		return n.Token
+	case *IntegerLiteral:
+		return n.Token
+	case *FloatLiteral:
+		return n.Token
+	case *StringLiteral:
+		return n.Token
+	case *Boolean:
+		return n.Token
+	case *PrefixExpression:
+		return n.Token
+	case *InfixExpression:
+		return startToken(n.Left)
+	case *IfExpression:
+		return n.Token
+	case *FunctionLiteral:
+		return n.Token
+	case *CallExpression:
+		return startToken(n.Function)
+	case *BadStatement:
+		return n.Token
+	case *BadExpression:
+		return n.Token
+	default:
+		return token.Token{}
+	}
+}
+
+func end(node Node) token.Pos {
+	switch n := node.(type) {
+	case *Program:
+		if len(n.Statements) == 0 {
+			return token.Pos{}
+		}
+		return end(n.Statements[len(n.Statements)-1])
+	case *LetStatement:
+		return end(n.Value)
+	case *ReturnStatement:
+		return end(n.ReturnValue)
+	case *ExpressionStatement:
+		return end(n.Expression)
+	case *BlockStatement:
+		return n.Rbrace.Span().End
+	case *PrefixExpression:
+		return end(n.Right)
+	case *InfixExpression:
+		return end(n.Right)
+	case *IfExpression:
+		if n.Alternative != nil {
+			return end(n.Alternative)
+		}
+		return end(n.Consequence)
+	case *FunctionLiteral:
+		return end(n.Body)
+	case *CallExpression:
+		return n.Rparen.Span().End
+	case *Identifier:
+		return n.Token.Span().End
+	case *IntegerLiteral:
+		return n.Token.Span().End
+	case *FloatLiteral:
+		return n.Token.Span().End
+	case *StringLiteral:
+		return n.Token.Span().End
+	case *Boolean:
+		return n.Token.Span().End
+	case *BadStatement:
+		return n.End
+	case *BadExpression:
+		return n.End
+	default:
+		return token.Pos{}
+	}
+}
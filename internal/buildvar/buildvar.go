@@ -0,0 +1,114 @@
+// Package buildvar holds information about how hai was built. The variables
+// are set at link time (see BuildHai in magefiles/magefile.go), e.g.:
+//
+//	go build -ldflags '-X "github.com/danbrakeley/hai/internal/buildvar.Version=v1.2.3"'
+package buildvar
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+var (
+	Version    string
+	Commit     string
+	BuildTime  string
+	ReleaseURL string
+)
+
+// Info describes a build of hai.
+type Info struct {
+	Version    string
+	Commit     string
+	BuildTime  string
+	GoVersion  string
+	ReleaseURL string
+}
+
+// Get returns the information set at link time. Anything that was not set is
+// filled in from the build info embedded by the go tool, where possible.
+func Get() Info {
+	bi, _ := debug.ReadBuildInfo()
+	return get(bi)
+}
+
+func get(bi *debug.BuildInfo) Info {
+	info := Info{
+		Version:    Version,
+		Commit:     Commit,
+		BuildTime:  BuildTime,
+		GoVersion:  runtime.Version(),
+		ReleaseURL: ReleaseURL,
+	}
+	if bi == nil {
+		return withDefaults(info)
+	}
+
+	if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	if bi.GoVersion != "" {
+		info.GoVersion = bi.GoVersion
+	}
+
+	var revision string
+	var modified bool
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if info.Commit == "" && revision != "" {
+		info.Commit = shortRevision(revision)
+		if modified {
+			info.Commit += "-dirty"
+		}
+	}
+
+	return withDefaults(info)
+}
+
+func withDefaults(info Info) Info {
+	if info.Version == "" {
+		info.Version = "(dev)"
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	if info.ReleaseURL == "" {
+		info.ReleaseURL = "https://github.com/danbrakeley/hai"
+	}
+	return info
+}
+
+func shortRevision(rev string) string {
+	const length = 7
+	if len(rev) > length {
+		return rev[:length]
+	}
+	return rev
+}
+
+// Summary returns a one line description of the build.
+func (i Info) Summary() string {
+	return fmt.Sprintf("hai %s (commit %s, %s)", i.Version, i.Commit, i.GoVersion)
+}
+
+// String returns a full, multi-line description of the build.
+func (i Info) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "hai %s\n", i.Version)
+	fmt.Fprintf(&sb, "commit:     %s\n", i.Commit)
+	fmt.Fprintf(&sb, "built:      %s\n", i.BuildTime)
+	fmt.Fprintf(&sb, "go version: %s\n", i.GoVersion)
+	fmt.Fprintf(&sb, "release:    %s\n", i.ReleaseURL)
+	return sb.String()
+}
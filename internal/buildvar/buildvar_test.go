@@ -0,0 +1,44 @@
+package buildvar
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	bi := &debug.BuildInfo{
+		GoVersion: "go1.99",
+		Main:      debug.Module{Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	info := get(bi)
+	expected := Info{
+		Version:    "(dev)",
+		Commit:     "0123456-dirty",
+		BuildTime:  "unknown",
+		GoVersion:  "go1.99",
+		ReleaseURL: "https://github.com/danbrakeley/hai",
+	}
+	if info != expected {
+		t.Errorf("expected %+v, got %+v", expected, info)
+	}
+
+	// values set at link time win
+	Version, Commit, BuildTime = "v1.2.3", "abcdef0", "2024-01-02T03:04:05Z"
+	defer func() { Version, Commit, BuildTime = "", "", "" }()
+
+	info = get(bi)
+	if info.Version != "v1.2.3" || info.Commit != "abcdef0" || info.BuildTime != "2024-01-02T03:04:05Z" {
+		t.Errorf("link time values were not used: %+v", info)
+	}
+
+	bi.Main.Version = "v0.1.0"
+	Version = ""
+	if info := get(bi); info.Version != "v0.1.0" {
+		t.Errorf("expected module version v0.1.0, got %s", info.Version)
+	}
+}
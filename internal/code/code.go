@@ -0,0 +1,202 @@
+package code
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Instructions is a flat sequence of encoded opcodes and their operands.
+type Instructions []byte
+
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s\n", i, fmtInstruction(Opcode(ins[i]), def, operands))
+
+		i += 1 + read
+	}
+
+	return out.String()
+}
+
+func fmtInstruction(op Opcode, def *Definition, operands []int) string {
+	operandCount := len(def.OperandWidths)
+	if len(operands) != operandCount {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d\n", len(operands), operandCount)
+	}
+
+	switch operandCount {
+	case 0:
+		return op.String()
+	case 1:
+		return fmt.Sprintf("%s %d", op, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", op, operands[0], operands[1])
+	}
+
+	return fmt.Sprintf("ERROR: unhandled operandCount for %s\n", op)
+}
+
+//go:generate enumer -type=Opcode
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota
+	OpPop
+
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+
+	OpTrue
+	OpFalse
+	OpNull
+
+	OpEqual
+	OpNotEqual
+	OpGreaterThan
+	OpLessThan
+
+	OpMinus
+	OpBang
+
+	OpJumpNotTruthy
+	OpJump
+
+	OpGetGlobal
+	OpSetGlobal
+	OpGetLocal
+	OpSetLocal
+	OpGetFree
+	OpGetBuiltin
+
+	OpCall
+	OpReturnValue
+	OpReturn
+
+	OpClosure
+	OpCurrentClosure
+)
+
+// Definition describes the operands that follow an opcode.
+type Definition struct {
+	OperandWidths []int // size in bytes of each operand
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {[]int{2}}, // index into the constant pool
+	OpPop:      {[]int{}},
+
+	OpAdd: {[]int{}},
+	OpSub: {[]int{}},
+	OpMul: {[]int{}},
+	OpDiv: {[]int{}},
+
+	OpTrue:  {[]int{}},
+	OpFalse: {[]int{}},
+	OpNull:  {[]int{}},
+
+	OpEqual:       {[]int{}},
+	OpNotEqual:    {[]int{}},
+	OpGreaterThan: {[]int{}},
+	OpLessThan:    {[]int{}},
+
+	OpMinus: {[]int{}},
+	OpBang:  {[]int{}},
+
+	OpJumpNotTruthy: {[]int{2}}, // absolute offset to jump to
+	OpJump:          {[]int{2}}, // absolute offset to jump to
+
+	OpGetGlobal:  {[]int{2}}, // index of global
+	OpSetGlobal:  {[]int{2}}, // index of global
+	OpGetLocal:   {[]int{1}}, // index of local
+	OpSetLocal:   {[]int{1}}, // index of local
+	OpGetFree:    {[]int{1}}, // index of free variable
+	OpGetBuiltin: {[]int{1}}, // index into object.Builtins
+
+	OpCall:        {[]int{1}}, // number of arguments
+	OpReturnValue: {[]int{}},
+	OpReturn:      {[]int{}},
+
+	OpClosure:        {[]int{2, 1}}, // index of compiled function constant, number of free variables
+	OpCurrentClosure: {[]int{}},
+}
+
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Make encodes an instruction. Operands are stored big-endian, and are
+// truncated if they do not fit in their width, so it is up to the compiler
+// to keep within its limits.
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	instructionLen := 1
+	for _, w := range def.OperandWidths {
+		instructionLen += w
+	}
+
+	instruction := make([]byte, instructionLen)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, o := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
+		case 1:
+			instruction[offset] = byte(o)
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+// ReadOperands decodes the operands of an instruction, and returns them
+// along with how many bytes they took up.
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		}
+		offset += width
+	}
+
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+func ReadUint8(ins Instructions) uint8 {
+	return uint8(ins[0])
+}
@@ -0,0 +1,98 @@
+package code
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	cases := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+		{OpGetLocal, []int{255}, []byte{byte(OpGetLocal), 255}},
+		{OpClosure, []int{65534, 255}, []byte{byte(OpClosure), 255, 254, 255}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.op.String(), func(t *testing.T) {
+			instruction := Make(tc.op, tc.operands...)
+
+			if len(instruction) != len(tc.expected) {
+				t.Fatalf("expected instruction length %d, got %d", len(tc.expected), len(instruction))
+			}
+			for i, b := range tc.expected {
+				if instruction[i] != b {
+					t.Errorf("expected byte %d to be %d, got %d", i, b, instruction[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	instructions := []Instructions{
+		Make(OpAdd),
+		Make(OpGetLocal, 1),
+		Make(OpConstant, 2),
+		Make(OpConstant, 65535),
+		Make(OpClosure, 65535, 255),
+	}
+
+	expected := `0000 OpAdd
+0001 OpGetLocal 1
+0003 OpConstant 2
+0006 OpConstant 65535
+0009 OpClosure 65535 255
+`
+
+	concatted := Instructions{}
+	for _, ins := range instructions {
+		concatted = append(concatted, ins...)
+	}
+
+	if concatted.String() != expected {
+		t.Errorf("instructions wrongly formatted.\nexpected:\n%s\ngot:\n%s", expected, concatted.String())
+	}
+}
+
+func TestReadOperands(t *testing.T) {
+	cases := []struct {
+		op        Opcode
+		operands  []int
+		bytesRead int
+	}{
+		{OpConstant, []int{65535}, 2},
+		{OpGetLocal, []int{255}, 1},
+		{OpClosure, []int{65535, 255}, 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.op.String(), func(t *testing.T) {
+			instruction := Make(tc.op, tc.operands...)
+
+			def, err := Lookup(byte(tc.op))
+			if err != nil {
+				t.Fatalf("definition not found: %v", err)
+			}
+
+			operandsRead, n := ReadOperands(def, instruction[1:])
+			if n != tc.bytesRead {
+				t.Fatalf("expected %d bytes read, got %d", tc.bytesRead, n)
+			}
+			for i, want := range tc.operands {
+				if operandsRead[i] != want {
+					t.Errorf("expected operand %d to be %d, got %d", i, want, operandsRead[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEveryOpcodeIsDefined(t *testing.T) {
+	for _, op := range OpcodeValues() {
+		if _, err := Lookup(byte(op)); err != nil {
+			t.Errorf("%s: %v", op, err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Code generated by "enumer -type=Opcode"; DO NOT EDIT.
+
+package code
+
+import (
+	"fmt"
+	"strings"
+)
+
+const _OpcodeName = "OpConstantOpPopOpAddOpSubOpMulOpDivOpTrueOpFalseOpNullOpEqualOpNotEqualOpGreaterThanOpLessThanOpMinusOpBangOpJumpNotTruthyOpJumpOpGetGlobalOpSetGlobalOpGetLocalOpSetLocalOpGetFreeOpGetBuiltinOpCallOpReturnValueOpReturnOpClosureOpCurrentClosure"
+
+var _OpcodeIndex = [...]uint8{0, 10, 15, 20, 25, 30, 35, 41, 48, 54, 61, 71, 84, 94, 101, 107, 122, 128, 139, 150, 160, 170, 179, 191, 197, 210, 218, 227, 243}
+
+const _OpcodeLowerName = "opconstantoppopopaddopsubopmulopdivoptrueopfalseopnullopequalopnotequalopgreaterthanoplessthanopminusopbangopjumpnottruthyopjumpopgetglobalopsetglobalopgetlocalopsetlocalopgetfreeopgetbuiltinopcallopreturnvalueopreturnopclosureopcurrentclosure"
+
+func (i Opcode) String() string {
+	if i >= Opcode(len(_OpcodeIndex)-1) {
+		return fmt.Sprintf("Opcode(%d)", i)
+	}
+	return _OpcodeName[_OpcodeIndex[i]:_OpcodeIndex[i+1]]
+}
+
+// An "invalid array index" compiler error signifies that the constant values have changed.
+// Re-run the stringer command to generate them again.
+func _OpcodeNoOp() {
+	var x [1]struct{}
+	_ = x[OpConstant-(0)]
+	_ = x[OpPop-(1)]
+	_ = x[OpAdd-(2)]
+	_ = x[OpSub-(3)]
+	_ = x[OpMul-(4)]
+	_ = x[OpDiv-(5)]
+	_ = x[OpTrue-(6)]
+	_ = x[OpFalse-(7)]
+	_ = x[OpNull-(8)]
+	_ = x[OpEqual-(9)]
+	_ = x[OpNotEqual-(10)]
+	_ = x[OpGreaterThan-(11)]
+	_ = x[OpLessThan-(12)]
+	_ = x[OpMinus-(13)]
+	_ = x[OpBang-(14)]
+	_ = x[OpJumpNotTruthy-(15)]
+	_ = x[OpJump-(16)]
+	_ = x[OpGetGlobal-(17)]
+	_ = x[OpSetGlobal-(18)]
+	_ = x[OpGetLocal-(19)]
+	_ = x[OpSetLocal-(20)]
+	_ = x[OpGetFree-(21)]
+	_ = x[OpGetBuiltin-(22)]
+	_ = x[OpCall-(23)]
+	_ = x[OpReturnValue-(24)]
+	_ = x[OpReturn-(25)]
+	_ = x[OpClosure-(26)]
+	_ = x[OpCurrentClosure-(27)]
+}
+
+var _OpcodeValues = []Opcode{OpConstant, OpPop, OpAdd, OpSub, OpMul, OpDiv, OpTrue, OpFalse, OpNull, OpEqual, OpNotEqual, OpGreaterThan, OpLessThan, OpMinus, OpBang, OpJumpNotTruthy, OpJump, OpGetGlobal, OpSetGlobal, OpGetLocal, OpSetLocal, OpGetFree, OpGetBuiltin, OpCall, OpReturnValue, OpReturn, OpClosure, OpCurrentClosure}
+
+var _OpcodeNameToValueMap = map[string]Opcode{
+	_OpcodeName[0:10]:         OpConstant,
+	_OpcodeLowerName[0:10]:    OpConstant,
+	_OpcodeName[10:15]:        OpPop,
+	_OpcodeLowerName[10:15]:   OpPop,
+	_OpcodeName[15:20]:        OpAdd,
+	_OpcodeLowerName[15:20]:   OpAdd,
+	_OpcodeName[20:25]:        OpSub,
+	_OpcodeLowerName[20:25]:   OpSub,
+	_OpcodeName[25:30]:        OpMul,
+	_OpcodeLowerName[25:30]:   OpMul,
+	_OpcodeName[30:35]:        OpDiv,
+	_OpcodeLowerName[30:35]:   OpDiv,
+	_OpcodeName[35:41]:        OpTrue,
+	_OpcodeLowerName[35:41]:   OpTrue,
+	_OpcodeName[41:48]:        OpFalse,
+	_OpcodeLowerName[41:48]:   OpFalse,
+	_OpcodeName[48:54]:        OpNull,
+	_OpcodeLowerName[48:54]:   OpNull,
+	_OpcodeName[54:61]:        OpEqual,
+	_OpcodeLowerName[54:61]:   OpEqual,
+	_OpcodeName[61:71]:        OpNotEqual,
+	_OpcodeLowerName[61:71]:   OpNotEqual,
+	_OpcodeName[71:84]:        OpGreaterThan,
+	_OpcodeLowerName[71:84]:   OpGreaterThan,
+	_OpcodeName[84:94]:        OpLessThan,
+	_OpcodeLowerName[84:94]:   OpLessThan,
+	_OpcodeName[94:101]:       OpMinus,
+	_OpcodeLowerName[94:101]:  OpMinus,
+	_OpcodeName[101:107]:      OpBang,
+	_OpcodeLowerName[101:107]: OpBang,
+	_OpcodeName[107:122]:      OpJumpNotTruthy,
+	_OpcodeLowerName[107:122]: OpJumpNotTruthy,
+	_OpcodeName[122:128]:      OpJump,
+	_OpcodeLowerName[122:128]: OpJump,
+	_OpcodeName[128:139]:      OpGetGlobal,
+	_OpcodeLowerName[128:139]: OpGetGlobal,
+	_OpcodeName[139:150]:      OpSetGlobal,
+	_OpcodeLowerName[139:150]: OpSetGlobal,
+	_OpcodeName[150:160]:      OpGetLocal,
+	_OpcodeLowerName[150:160]: OpGetLocal,
+	_OpcodeName[160:170]:      OpSetLocal,
+	_OpcodeLowerName[160:170]: OpSetLocal,
+	_OpcodeName[170:179]:      OpGetFree,
+	_OpcodeLowerName[170:179]: OpGetFree,
+	_OpcodeName[179:191]:      OpGetBuiltin,
+	_OpcodeLowerName[179:191]: OpGetBuiltin,
+	_OpcodeName[191:197]:      OpCall,
+	_OpcodeLowerName[191:197]: OpCall,
+	_OpcodeName[197:210]:      OpReturnValue,
+	_OpcodeLowerName[197:210]: OpReturnValue,
+	_OpcodeName[210:218]:      OpReturn,
+	_OpcodeLowerName[210:218]: OpReturn,
+	_OpcodeName[218:227]:      OpClosure,
+	_OpcodeLowerName[218:227]: OpClosure,
+	_OpcodeName[227:243]:      OpCurrentClosure,
+	_OpcodeLowerName[227:243]: OpCurrentClosure,
+}
+
+var _OpcodeNames = []string{
+	_OpcodeName[0:10],
+	_OpcodeName[10:15],
+	_OpcodeName[15:20],
+	_OpcodeName[20:25],
+	_OpcodeName[25:30],
+	_OpcodeName[30:35],
+	_OpcodeName[35:41],
+	_OpcodeName[41:48],
+	_OpcodeName[48:54],
+	_OpcodeName[54:61],
+	_OpcodeName[61:71],
+	_OpcodeName[71:84],
+	_OpcodeName[84:94],
+	_OpcodeName[94:101],
+	_OpcodeName[101:107],
+	_OpcodeName[107:122],
+	_OpcodeName[122:128],
+	_OpcodeName[128:139],
+	_OpcodeName[139:150],
+	_OpcodeName[150:160],
+	_OpcodeName[160:170],
+	_OpcodeName[170:179],
+	_OpcodeName[179:191],
+	_OpcodeName[191:197],
+	_OpcodeName[197:210],
+	_OpcodeName[210:218],
+	_OpcodeName[218:227],
+	_OpcodeName[227:243],
+}
+
+// OpcodeString retrieves an enum value from the enum constants string name.
+// Throws an error if the param is not part of the enum.
+func OpcodeString(s string) (Opcode, error) {
+	if val, ok := _OpcodeNameToValueMap[s]; ok {
+		return val, nil
+	}
+
+	if val, ok := _OpcodeNameToValueMap[strings.ToLower(s)]; ok {
+		return val, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Opcode values", s)
+}
+
+// OpcodeValues returns all values of the enum
+func OpcodeValues() []Opcode {
+	return _OpcodeValues
+}
+
+// OpcodeStrings returns a slice of all String values of the enum
+func OpcodeStrings() []string {
+	strs := make([]string, len(_OpcodeNames))
+	copy(strs, _OpcodeNames)
+	return strs
+}
+
+// IsAOpcode returns "true" if the value is listed in the enum definition. "false" otherwise
+func (i Opcode) IsAOpcode() bool {
+	for _, v := range _OpcodeValues {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
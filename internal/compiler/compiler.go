@@ -0,0 +1,455 @@
+package compiler
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/code"
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// The most of each thing that a program can have, so that it can be encoded
+// in the operands of the instructions that refer to it.
+const (
+	MaxConstants     = math.MaxUint16
+	MaxGlobals       = math.MaxUint16
+	MaxLocals        = math.MaxUint8 // including parameters
+	MaxFreeVariables = math.MaxUint8 // per function
+	MaxArguments     = math.MaxUint8
+	MaxJumpOffset    = math.MaxUint16 // in bytes, from the start of the function
+)
+
+type Compiler struct {
+	constants   []object.Object
+	symbolTable *SymbolTable
+
+	scopes     []CompilationScope
+	scopeIndex int
+}
+
+type EmittedInstruction struct {
+	Opcode   code.Opcode
+	Position int
+}
+
+// CompilationScope holds the instructions emitted for a single function body
+// (or for the top level of the program).
+type CompilationScope struct {
+	instructions        code.Instructions
+	lastInstruction     EmittedInstruction
+	previousInstruction EmittedInstruction
+}
+
+// Bytecode is the output of the compiler, and the input to the vm.
+type Bytecode struct {
+	Instructions code.Instructions
+	Constants    []object.Object
+}
+
+func New() *Compiler {
+	return NewWithState(NewSymbolTable(), []object.Object{})
+}
+
+// NewWithState creates a compiler that continues on from the given global
+// symbols and constants, so that separately compiled programs can share them.
+func NewWithState(s *SymbolTable, constants []object.Object) *Compiler {
+	return &Compiler{
+		constants:   constants,
+		symbolTable: s,
+		scopes:      []CompilationScope{{}},
+	}
+}
+
+// Compile lowers node into bytecode. Errors are returned as diag.Diagnostic values.
+func (c *Compiler) Compile(node ast.Node) error {
+	switch node := node.(type) {
+
+	// Statements
+
+	case *ast.Program:
+		for _, s := range node.Statements {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+
+	case *ast.ExpressionStatement:
+		if err := c.Compile(node.Expression); err != nil {
+			return err
+		}
+		c.emit(code.OpPop)
+
+	case *ast.BlockStatement:
+		for _, s := range node.Statements {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+
+	case *ast.LetStatement:
+		var err error
+		if fl, ok := node.Value.(*ast.FunctionLiteral); ok {
+			err = c.compileFunctionLiteral(fl, node.Name.Value)
+		} else {
+			err = c.Compile(node.Value)
+		}
+		if err != nil {
+			return err
+		}
+
+		symbol, err := c.define(node.Name)
+		if err != nil {
+			return err
+		}
+		if symbol.Scope == GlobalScope {
+			c.emit(code.OpSetGlobal, symbol.Index)
+		} else {
+			c.emit(code.OpSetLocal, symbol.Index)
+		}
+
+	case *ast.ReturnStatement:
+		if err := c.Compile(node.ReturnValue); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+
+	// Expressions
+
+	case *ast.IntegerLiteral:
+		integer := &object.Integer{Value: node.Value, Big: node.Big}
+		return c.emitConstant(integer, node.Token)
+
+	case *ast.FloatLiteral:
+		float := &object.Float{Value: node.Value}
+		return c.emitConstant(float, node.Token)
+
+	case *ast.StringLiteral:
+		str := &object.String{Value: node.Value}
+		return c.emitConstant(str, node.Token)
+
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+
+	case *ast.Identifier:
+		symbol, ok := c.symbolTable.Resolve(node.Value)
+		if !ok {
+			if i := builtinIndex(node.Value); i >= 0 {
+				c.emit(code.OpGetBuiltin, i)
+				break
+			}
+			return diag.Errorf(diag.UndefinedIdentifier, node.Token.Span(), "identifier not found: %s", node.Value)
+		}
+		c.loadSymbol(symbol)
+
+	case *ast.PrefixExpression:
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+
+		switch node.Operator {
+		case "!":
+			c.emit(code.OpBang)
+		case "-":
+			c.emit(code.OpMinus)
+		default:
+			return fmt.Errorf("unknown operator %s", node.Operator)
+		}
+
+	case *ast.InfixExpression:
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+
+		switch node.Operator {
+		case "+":
+			c.emit(code.OpAdd)
+		case "-":
+			c.emit(code.OpSub)
+		case "*":
+			c.emit(code.OpMul)
+		case "/":
+			c.emit(code.OpDiv)
+		case ">":
+			c.emit(code.OpGreaterThan)
+		case "<":
+			c.emit(code.OpLessThan)
+		case "==":
+			c.emit(code.OpEqual)
+		case "!=":
+			c.emit(code.OpNotEqual)
+		default:
+			return fmt.Errorf("unknown operator %s", node.Operator)
+		}
+
+	case *ast.IfExpression:
+		if err := c.Compile(node.Condition); err != nil {
+			return err
+		}
+
+		// the jump target is filled in once we know where the consequence ends
+		jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 9999)
+
+		if err := c.compileBlockValue(node.Consequence); err != nil {
+			return err
+		}
+
+		jumpPos := c.emit(code.OpJump, 9999)
+		c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+
+		if node.Alternative == nil {
+			c.emit(code.OpNull)
+		} else {
+			if err := c.compileBlockValue(node.Alternative); err != nil {
+				return err
+			}
+		}
+
+		if len(c.currentInstructions()) > MaxJumpOffset {
+			return diag.Errorf(diag.FunctionTooLarge, node.Token.Span(),
+				"too much code to jump over (the most is %d bytes of instructions)", MaxJumpOffset)
+		}
+		c.changeOperand(jumpPos, len(c.currentInstructions()))
+
+	case *ast.FunctionLiteral:
+		return c.compileFunctionLiteral(node, "")
+
+	case *ast.CallExpression:
+		if err := c.Compile(node.Function); err != nil {
+			return err
+		}
+		if len(node.Arguments) > MaxArguments {
+			return diag.Errorf(diag.TooManyArguments, node.Token.Span(),
+				"too many arguments (%d, the most is %d)", len(node.Arguments), MaxArguments)
+		}
+		for _, a := range node.Arguments {
+			if err := c.Compile(a); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpCall, len(node.Arguments))
+
+	default:
+		return fmt.Errorf("unhandled node type: %T", node)
+	}
+
+	return nil
+}
+
+// compileBlockValue compiles a block so that it leaves exactly one value on
+// the stack: the value of its last expression statement, or null.
+func (c *Compiler) compileBlockValue(block *ast.BlockStatement) error {
+	if err := c.Compile(block); err != nil {
+		return err
+	}
+
+	if c.lastInstructionIs(code.OpPop) {
+		c.removeLastPop()
+	} else {
+		c.emit(code.OpNull)
+	}
+	return nil
+}
+
+// compileFunctionLiteral compiles fl into a closure. If the function is being
+// bound to a name, that name is passed in so the function can call itself.
+func (c *Compiler) compileFunctionLiteral(fl *ast.FunctionLiteral, name string) error {
+	c.enterScope()
+
+	if len(name) > 0 {
+		c.symbolTable.DefineFunctionName(name)
+	}
+
+	for _, p := range fl.Parameters {
+		if _, err := c.define(p); err != nil {
+			c.leaveScope()
+			return err
+		}
+	}
+
+	if err := c.Compile(fl.Body); err != nil {
+		c.leaveScope()
+		return err
+	}
+
+	if c.lastInstructionIs(code.OpPop) {
+		c.replaceLastPopWithReturn()
+	}
+	if !c.lastInstructionIs(code.OpReturnValue) {
+		c.emit(code.OpReturn)
+	}
+
+	freeSymbols := c.symbolTable.FreeSymbols
+	numLocals := c.symbolTable.NumDefinitions()
+	instructions := c.leaveScope()
+
+	if len(freeSymbols) > MaxFreeVariables {
+		return diag.Errorf(diag.TooManyFreeVariables, fl.Token.Span(),
+			"function refers to too many variables of enclosing functions (%d, the most is %d)",
+			len(freeSymbols), MaxFreeVariables)
+	}
+
+	for _, s := range freeSymbols {
+		c.loadSymbol(s)
+	}
+
+	compiledFn := &object.CompiledFunction{
+		Instructions:  instructions,
+		NumLocals:     numLocals,
+		NumParameters: len(fl.Parameters),
+	}
+	index, err := c.addConstant(compiledFn, fl.Token)
+	if err != nil {
+		return err
+	}
+	c.emit(code.OpClosure, index, len(freeSymbols))
+	return nil
+}
+
+// define binds the name of ident in the current scope, unless the scope
+// already has as many names as it can hold.
+func (c *Compiler) define(ident *ast.Identifier) (Symbol, error) {
+	symbol := c.symbolTable.Define(ident.Value)
+	switch {
+	case symbol.Scope == GlobalScope && symbol.Index >= MaxGlobals:
+		return symbol, diag.Errorf(diag.TooManyGlobals, ident.Token.Span(),
+			"too many global variables (the most is %d)", MaxGlobals)
+	case symbol.Scope == LocalScope && symbol.Index >= MaxLocals:
+		return symbol, diag.Errorf(diag.TooManyLocals, ident.Token.Span(),
+			"too many local variables and parameters in one function (the most is %d)", MaxLocals)
+	}
+	return symbol, nil
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		Constants:    c.constants,
+	}
+}
+
+func (c *Compiler) loadSymbol(s Symbol) {
+	switch s.Scope {
+	case GlobalScope:
+		c.emit(code.OpGetGlobal, s.Index)
+	case LocalScope:
+		c.emit(code.OpGetLocal, s.Index)
+	case FreeScope:
+		c.emit(code.OpGetFree, s.Index)
+	case FunctionScope:
+		c.emit(code.OpCurrentClosure)
+	}
+}
+
+// addConstant adds obj to the constant pool, and returns its index. tok is
+// what to report the error at, if the pool is full.
+func (c *Compiler) addConstant(obj object.Object, tok token.Token) (int, error) {
+	if len(c.constants) >= MaxConstants {
+		return 0, diag.Errorf(diag.TooManyConstants, tok.Span(),
+			"too many constants (the most is %d)", MaxConstants)
+	}
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1, nil
+}
+
+func (c *Compiler) emitConstant(obj object.Object, tok token.Token) error {
+	index, err := c.addConstant(obj, tok)
+	if err != nil {
+		return err
+	}
+	c.emit(code.OpConstant, index)
+	return nil
+}
+
+// emit adds an instruction to the current scope, and returns its position
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	ins := code.Make(op, operands...)
+	pos := c.addInstruction(ins)
+	c.setLastInstruction(op, pos)
+	return pos
+}
+
+func (c *Compiler) addInstruction(ins []byte) int {
+	posNewInstruction := len(c.currentInstructions())
+	c.scopes[c.scopeIndex].instructions = append(c.currentInstructions(), ins...)
+	return posNewInstruction
+}
+
+func (c *Compiler) setLastInstruction(op code.Opcode, pos int) {
+	previous := c.scopes[c.scopeIndex].lastInstruction
+	last := EmittedInstruction{Opcode: op, Position: pos}
+
+	c.scopes[c.scopeIndex].previousInstruction = previous
+	c.scopes[c.scopeIndex].lastInstruction = last
+}
+
+func (c *Compiler) currentInstructions() code.Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) lastInstructionIs(op code.Opcode) bool {
+	if len(c.currentInstructions()) == 0 {
+		return false
+	}
+	return c.scopes[c.scopeIndex].lastInstruction.Opcode == op
+}
+
+func (c *Compiler) removeLastPop() {
+	last := c.scopes[c.scopeIndex].lastInstruction
+	previous := c.scopes[c.scopeIndex].previousInstruction
+
+	c.scopes[c.scopeIndex].instructions = c.currentInstructions()[:last.Position]
+	c.scopes[c.scopeIndex].lastInstruction = previous
+}
+
+func (c *Compiler) replaceInstruction(pos int, newInstruction []byte) {
+	ins := c.currentInstructions()
+	copy(ins[pos:], newInstruction)
+}
+
+func (c *Compiler) replaceLastPopWithReturn() {
+	lastPos := c.scopes[c.scopeIndex].lastInstruction.Position
+	c.replaceInstruction(lastPos, code.Make(code.OpReturnValue))
+	c.scopes[c.scopeIndex].lastInstruction.Opcode = code.OpReturnValue
+}
+
+func (c *Compiler) changeOperand(opPos int, operand int) {
+	op := code.Opcode(c.currentInstructions()[opPos])
+	c.replaceInstruction(opPos, code.Make(op, operand))
+}
+
+func (c *Compiler) enterScope() {
+	c.scopes = append(c.scopes, CompilationScope{})
+	c.scopeIndex++
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+func (c *Compiler) leaveScope() code.Instructions {
+	instructions := c.currentInstructions()
+
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+	c.symbolTable = c.symbolTable.Outer
+
+	return instructions
+}
+
+// builtinIndex returns the index of the named builtin in object.Builtins, or
+// -1 if there is no such builtin.
+func builtinIndex(name string) int {
+	for i, b := range object.Builtins {
+		if b.Name == name {
+			return i
+		}
+	}
+	return -1
+}
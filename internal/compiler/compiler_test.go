@@ -0,0 +1,503 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/code"
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/parser"
+)
+
+type compilerTestCase struct {
+	input                string
+	expectedConstants    []any
+	expectedInstructions []code.Instructions
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	runCompilerTests(t, []compilerTestCase{
+		{
+			input:             "1 + 2",
+			expectedConstants: []any{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "1; 2",
+			expectedConstants: []any{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "2 / 1 * 3 - 4",
+			expectedConstants: []any{2, 1, 3, 4},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpDiv),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpMul),
+				code.Make(code.OpConstant, 3),
+				code.Make(code.OpSub),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "-1",
+			expectedConstants: []any{1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpMinus),
+				code.Make(code.OpPop),
+			},
+		},
+	})
+}
+
+func TestBooleanExpressions(t *testing.T) {
+	runCompilerTests(t, []compilerTestCase{
+		{
+			input:             "true",
+			expectedConstants: []any{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTrue),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "1 < 2",
+			expectedConstants: []any{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpLessThan),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "true != false",
+			expectedConstants: []any{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTrue),
+				code.Make(code.OpFalse),
+				code.Make(code.OpNotEqual),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "!true",
+			expectedConstants: []any{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTrue),
+				code.Make(code.OpBang),
+				code.Make(code.OpPop),
+			},
+		},
+	})
+}
+
+func TestConditionals(t *testing.T) {
+	runCompilerTests(t, []compilerTestCase{
+		{
+			input:             "if (true) { 10 }; 3333;",
+			expectedConstants: []any{10, 3333},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 10),
+				// 0004
+				code.Make(code.OpConstant, 0),
+				// 0007
+				code.Make(code.OpJump, 11),
+				// 0010
+				code.Make(code.OpNull),
+				// 0011
+				code.Make(code.OpPop),
+				// 0012
+				code.Make(code.OpConstant, 1),
+				// 0015
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "if (true) { 10 } else { 20 }; 3333;",
+			expectedConstants: []any{10, 20, 3333},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 10),
+				// 0004
+				code.Make(code.OpConstant, 0),
+				// 0007
+				code.Make(code.OpJump, 13),
+				// 0010
+				code.Make(code.OpConstant, 1),
+				// 0013
+				code.Make(code.OpPop),
+				// 0014
+				code.Make(code.OpConstant, 2),
+				// 0017
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "if (true) { let x = 1; }",
+			expectedConstants: []any{1},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 14),
+				// 0004
+				code.Make(code.OpConstant, 0),
+				// 0007
+				code.Make(code.OpSetGlobal, 0),
+				// 0010
+				code.Make(code.OpNull),
+				// 0011
+				code.Make(code.OpJump, 15),
+				// 0014
+				code.Make(code.OpNull),
+				// 0015
+				code.Make(code.OpPop),
+			},
+		},
+	})
+}
+
+func TestGlobalLetStatements(t *testing.T) {
+	runCompilerTests(t, []compilerTestCase{
+		{
+			input:             "let one = 1; let two = one; two;",
+			expectedConstants: []any{1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpSetGlobal, 1),
+				code.Make(code.OpGetGlobal, 1),
+				code.Make(code.OpPop),
+			},
+		},
+	})
+}
+
+func TestFunctions(t *testing.T) {
+	runCompilerTests(t, []compilerTestCase{
+		{
+			input: "fn() { return 5 + 10; }",
+			expectedConstants: []any{
+				5,
+				10,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpConstant, 1),
+					code.Make(code.OpAdd),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 2, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn() { 1; 2 }",
+			expectedConstants: []any{
+				1,
+				2,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpPop),
+					code.Make(code.OpConstant, 1),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 2, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn() { }",
+			expectedConstants: []any{
+				[]code.Instructions{
+					code.Make(code.OpReturn),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 0, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "let f = fn(a, b) { let c = a; c }; f(1, 2);",
+			expectedConstants: []any{
+				[]code.Instructions{
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpSetLocal, 2),
+					code.Make(code.OpGetLocal, 2),
+					code.Make(code.OpReturnValue),
+				},
+				1,
+				2,
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 0, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpCall, 2),
+				code.Make(code.OpPop),
+			},
+		},
+	})
+}
+
+func TestClosures(t *testing.T) {
+	runCompilerTests(t, []compilerTestCase{
+		{
+			input: "fn(a) { fn(b) { a + b } }",
+			expectedConstants: []any{
+				[]code.Instructions{
+					code.Make(code.OpGetFree, 0),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpAdd),
+					code.Make(code.OpReturnValue),
+				},
+				[]code.Instructions{
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpClosure, 0, 1),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	})
+}
+
+func TestRecursiveFunctions(t *testing.T) {
+	runCompilerTests(t, []compilerTestCase{
+		{
+			input: "let countDown = fn(x) { countDown(x - 1); }; countDown(1);",
+			expectedConstants: []any{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpCurrentClosure),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSub),
+					code.Make(code.OpCall, 1),
+					code.Make(code.OpReturnValue),
+				},
+				1,
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpCall, 1),
+				code.Make(code.OpPop),
+			},
+		},
+	})
+}
+
+func TestBuiltins(t *testing.T) {
+	runCompilerTests(t, []compilerTestCase{
+		{
+			input:             `len("")`,
+			expectedConstants: []any{""},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpGetBuiltin, 0),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpCall, 1),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "let len = 1; len",
+			expectedConstants: []any{1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	})
+}
+
+func TestUndefinedIdentifier(t *testing.T) {
+	program := parse(t, "let a = 1;\nfn() { a + b }")
+
+	err := New().Compile(program)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	d, ok := err.(diag.Diagnostic)
+	if !ok {
+		t.Fatalf("expected diag.Diagnostic, got %T", err)
+	}
+	if d.Code != diag.UndefinedIdentifier || d.Span.Start.Line != 2 || d.Span.Start.Column != 12 {
+		t.Errorf("unexpected diagnostic: %s", d.Error())
+	}
+}
+
+func TestLimits(t *testing.T) {
+	// repeat joins n copies of format, each given its index
+	repeat := func(n int, format, sep string) string {
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = fmt.Sprintf(format, i)
+		}
+		return strings.Join(parts, sep)
+	}
+	params := func(prefix string, n int) string {
+		return repeat(n, prefix+"%d", ", ")
+	}
+	closure := func(outer, inner int) string {
+		return fmt.Sprintf("fn(%s) { fn(%s) { fn() { %s; %s } } }",
+			params("a", outer), params("b", inner),
+			repeat(outer, "a%d", "; "), repeat(inner, "b%d", "; "))
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		code  diag.Code // empty if it should compile
+	}{
+		{"constants", repeat(MaxConstants, "%d;", ""), ""},
+		{"too many constants", repeat(MaxConstants+1, "%d;", ""), diag.TooManyConstants},
+		{"globals", repeat(MaxGlobals, "let g%d = true;", ""), ""},
+		{"too many globals", repeat(MaxGlobals+1, "let g%d = true;", ""), diag.TooManyGlobals},
+		{"parameters", "fn(" + params("p", MaxLocals) + ") {}", ""},
+		{"too many parameters", "fn(" + params("p", MaxLocals+1) + ") {}", diag.TooManyLocals},
+		{"locals", "fn(p) { " + repeat(MaxLocals-1, "let l%d = true;", "") + " }", ""},
+		{"too many locals", "fn(p) { " + repeat(MaxLocals, "let l%d = true;", "") + " }", diag.TooManyLocals},
+		{"free variables", closure(200, MaxFreeVariables-200), ""},
+		{"too many free variables", closure(200, MaxFreeVariables-199), diag.TooManyFreeVariables},
+		{"arguments", "len(" + repeat(MaxArguments, "%d", ", ") + ")", ""},
+		{"too many arguments", "len(" + repeat(MaxArguments+1, "%d", ", ") + ")", diag.TooManyArguments},
+		// each statement is 4 bytes, and the if itself is 7 more
+		{"jump", "if (true) { " + repeat((MaxJumpOffset-7)/4, "%d", "; ") + " }", ""},
+		{"jump too far", "if (true) { " + repeat((MaxJumpOffset-7)/4+1, "%d", "; ") + " }", diag.FunctionTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Compile(parse(t, tt.input))
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			d, ok := err.(diag.Diagnostic)
+			if !ok {
+				t.Fatalf("expected diag.Diagnostic, got %T (%v)", err, err)
+			}
+			if d.Code != tt.code {
+				t.Errorf("expected %s, got %s", tt.code, d.Error())
+			}
+		})
+	}
+}
+
+func runCompilerTests(t *testing.T, cases []compilerTestCase) {
+	t.Helper()
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			program := parse(t, tc.input)
+
+			compiler := New()
+			if err := compiler.Compile(program); err != nil {
+				t.Fatalf("compiler error: %s", err)
+			}
+
+			bytecode := compiler.Bytecode()
+			testInstructions(t, tc.expectedInstructions, bytecode.Instructions)
+			testConstants(t, tc.expectedConstants, bytecode.Constants)
+		})
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); len(diags) > 0 {
+		t.Fatalf("unexpected parse error: %s", diags[0].Error())
+	}
+	return program
+}
+
+func testInstructions(t *testing.T, expected []code.Instructions, actual code.Instructions) {
+	t.Helper()
+	concatted := concatInstructions(expected)
+
+	if len(actual) != len(concatted) {
+		t.Fatalf("wrong instructions length.\nexpected:\n%s\ngot:\n%s", concatted, actual)
+	}
+	for i, ins := range concatted {
+		if actual[i] != ins {
+			t.Fatalf("wrong instruction at %d.\nexpected:\n%s\ngot:\n%s", i, concatted, actual)
+		}
+	}
+}
+
+func concatInstructions(s []code.Instructions) code.Instructions {
+	out := code.Instructions{}
+	for _, ins := range s {
+		out = append(out, ins...)
+	}
+	return out
+}
+
+func testConstants(t *testing.T, expected []any, actual []object.Object) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("wrong number of constants. expected %d, got %d", len(expected), len(actual))
+	}
+
+	for i, constant := range expected {
+		switch constant := constant.(type) {
+		case int:
+			result, ok := actual[i].(*object.Integer)
+			if !ok {
+				t.Fatalf("constant %d: expected *object.Integer, got %T", i, actual[i])
+			}
+			if result.Value != int64(constant) {
+				t.Errorf("constant %d: expected %d, got %d", i, constant, result.Value)
+			}
+		case []code.Instructions:
+			fn, ok := actual[i].(*object.CompiledFunction)
+			if !ok {
+				t.Fatalf("constant %d: expected *object.CompiledFunction, got %T", i, actual[i])
+			}
+			testInstructions(t, constant, fn.Instructions)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package compiler
+
+import "sort"
+
+//go:generate enumer -type=SymbolScope -transform=snake
+type SymbolScope uint8
+
+const (
+	GlobalScope SymbolScope = iota
+	LocalScope
+	FreeScope
+	FunctionScope
+)
+
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+}
+
+// SymbolTable tracks the names bound in a single scope. Function bodies get
+// their own table, which links to the table of the enclosing scope.
+type SymbolTable struct {
+	Outer *SymbolTable
+
+	store          map[string]Symbol
+	numDefinitions int
+
+	// FreeSymbols are the symbols from enclosing (non-global) scopes that are
+	// referenced from this scope, in the order they were first referenced.
+	FreeSymbols []Symbol
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{store: make(map[string]Symbol)}
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	return s
+}
+
+func (s *SymbolTable) Define(name string) Symbol {
+	if existing, ok := s.store[name]; ok && existing.Scope != FunctionScope && existing.Scope != FreeScope {
+		// redefining a name in the same scope reuses its slot
+		return existing
+	}
+
+	symbol := Symbol{Name: name, Index: s.numDefinitions}
+	if s.Outer == nil {
+		symbol.Scope = GlobalScope
+	} else {
+		symbol.Scope = LocalScope
+	}
+
+	s.store[name] = symbol
+	s.numDefinitions++
+	return symbol
+}
+
+// DefineFunctionName binds the name of the function currently being
+// compiled, so that it can refer to itself.
+func (s *SymbolTable) DefineFunctionName(name string) Symbol {
+	symbol := Symbol{Name: name, Index: 0, Scope: FunctionScope}
+	s.store[name] = symbol
+	return symbol
+}
+
+func (s *SymbolTable) defineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+
+	symbol := Symbol{Name: original.Name, Index: len(s.FreeSymbols) - 1, Scope: FreeScope}
+	s.store[original.Name] = symbol
+	return symbol
+}
+
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	obj, ok := s.store[name]
+	if ok || s.Outer == nil {
+		return obj, ok
+	}
+
+	obj, ok = s.Outer.Resolve(name)
+	if !ok {
+		return obj, ok
+	}
+
+	if obj.Scope == GlobalScope {
+		return obj, ok
+	}
+
+	return s.defineFree(obj), true
+}
+
+// NumDefinitions is the number of slots needed to hold the names defined
+// directly in this scope.
+func (s *SymbolTable) NumDefinitions() int {
+	return s.numDefinitions
+}
+
+// Names returns the names defined directly in this scope, in sorted order.
+func (s *SymbolTable) Names() []string {
+	names := make([]string, 0, len(s.store))
+	for name := range s.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Clone returns a copy of s that names can be defined in without affecting s.
+// The enclosing table is shared, not copied.
+func (s *SymbolTable) Clone() *SymbolTable {
+	clone := &SymbolTable{
+		Outer:          s.Outer,
+		store:          make(map[string]Symbol, len(s.store)),
+		numDefinitions: s.numDefinitions,
+		FreeSymbols:    append([]Symbol(nil), s.FreeSymbols...),
+	}
+	for name, symbol := range s.store {
+		clone.store[name] = symbol
+	}
+	return clone
+}
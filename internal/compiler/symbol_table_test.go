@@ -0,0 +1,110 @@
+package compiler
+
+import "testing"
+
+func TestDefineAndResolve(t *testing.T) {
+	global := NewSymbolTable()
+	a := global.Define("a")
+	b := global.Define("b")
+
+	local := NewEnclosedSymbolTable(global)
+	c := local.Define("c")
+
+	nested := NewEnclosedSymbolTable(local)
+	d := nested.Define("d")
+
+	expected := []struct {
+		table    *SymbolTable
+		name     string
+		expected Symbol
+	}{
+		{global, "a", Symbol{Name: "a", Scope: GlobalScope, Index: 0}},
+		{global, "b", Symbol{Name: "b", Scope: GlobalScope, Index: 1}},
+		{local, "a", a},
+		{local, "c", Symbol{Name: "c", Scope: LocalScope, Index: 0}},
+		{nested, "b", b},
+		{nested, "c", Symbol{Name: "c", Scope: FreeScope, Index: 0}},
+		{nested, "d", Symbol{Name: "d", Scope: LocalScope, Index: 0}},
+	}
+
+	for _, e := range expected {
+		result, ok := e.table.Resolve(e.name)
+		if !ok {
+			t.Errorf("name %s not resolvable", e.name)
+			continue
+		}
+		if result != e.expected {
+			t.Errorf("expected %s to resolve to %+v, got %+v", e.name, e.expected, result)
+		}
+	}
+
+	if c != (Symbol{Name: "c", Scope: LocalScope, Index: 0}) {
+		t.Errorf("unexpected symbol for c: %+v", c)
+	}
+	if d != (Symbol{Name: "d", Scope: LocalScope, Index: 0}) {
+		t.Errorf("unexpected symbol for d: %+v", d)
+	}
+
+	if len(nested.FreeSymbols) != 1 || nested.FreeSymbols[0] != c {
+		t.Errorf("expected nested free symbols to be [%+v], got %+v", c, nested.FreeSymbols)
+	}
+
+	if _, ok := global.Resolve("c"); ok {
+		t.Errorf("expected c to be unresolvable from the global scope")
+	}
+}
+
+func TestRedefineReusesSlot(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("a")
+	global.Define("b")
+	a := global.Define("a")
+
+	if a.Index != 0 {
+		t.Errorf("expected redefined a to keep index 0, got %d", a.Index)
+	}
+	if global.NumDefinitions() != 2 {
+		t.Errorf("expected 2 definitions, got %d", global.NumDefinitions())
+	}
+}
+
+func TestDefineFunctionName(t *testing.T) {
+	global := NewSymbolTable()
+	global.DefineFunctionName("a")
+
+	expected := Symbol{Name: "a", Scope: FunctionScope, Index: 0}
+	result, ok := global.Resolve("a")
+	if !ok {
+		t.Fatalf("function name a not resolvable")
+	}
+	if result != expected {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+
+	// shadowing the function name with a local
+	shadowed := global.Define("a")
+	if shadowed.Scope != GlobalScope {
+		t.Errorf("expected shadowing definition to be global, got %s", shadowed.Scope)
+	}
+}
+
+func TestClone(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("a")
+
+	clone := global.Clone()
+	b := clone.Define("b")
+	if b.Index != 1 {
+		t.Errorf("expected b to get index 1, got %d", b.Index)
+	}
+
+	if _, ok := global.Resolve("b"); ok {
+		t.Errorf("defining b in the clone changed the original")
+	}
+	if names := clone.Names(); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("unexpected names in clone: %v", names)
+	}
+	if names := global.Names(); len(names) != 1 || names[0] != "a" {
+		t.Errorf("unexpected names in original: %v", names)
+	}
+}
@@ -0,0 +1,86 @@
+// Code generated by "enumer -type=SymbolScope -transform=snake"; DO NOT EDIT.
+
+package compiler
+
+import (
+	"fmt"
+	"strings"
+)
+
+const _SymbolScopeName = "global_scopelocal_scopefree_scopefunction_scope"
+
+var _SymbolScopeIndex = [...]uint8{0, 12, 23, 33, 47}
+
+const _SymbolScopeLowerName = "global_scopelocal_scopefree_scopefunction_scope"
+
+func (i SymbolScope) String() string {
+	if i >= SymbolScope(len(_SymbolScopeIndex)-1) {
+		return fmt.Sprintf("SymbolScope(%d)", i)
+	}
+	return _SymbolScopeName[_SymbolScopeIndex[i]:_SymbolScopeIndex[i+1]]
+}
+
+// An "invalid array index" compiler error signifies that the constant values have changed.
+// Re-run the stringer command to generate them again.
+func _SymbolScopeNoOp() {
+	var x [1]struct{}
+	_ = x[GlobalScope-(0)]
+	_ = x[LocalScope-(1)]
+	_ = x[FreeScope-(2)]
+	_ = x[FunctionScope-(3)]
+}
+
+var _SymbolScopeValues = []SymbolScope{GlobalScope, LocalScope, FreeScope, FunctionScope}
+
+var _SymbolScopeNameToValueMap = map[string]SymbolScope{
+	_SymbolScopeName[0:12]:       GlobalScope,
+	_SymbolScopeLowerName[0:12]:  GlobalScope,
+	_SymbolScopeName[12:23]:      LocalScope,
+	_SymbolScopeLowerName[12:23]: LocalScope,
+	_SymbolScopeName[23:33]:      FreeScope,
+	_SymbolScopeLowerName[23:33]: FreeScope,
+	_SymbolScopeName[33:47]:      FunctionScope,
+	_SymbolScopeLowerName[33:47]: FunctionScope,
+}
+
+var _SymbolScopeNames = []string{
+	_SymbolScopeName[0:12],
+	_SymbolScopeName[12:23],
+	_SymbolScopeName[23:33],
+	_SymbolScopeName[33:47],
+}
+
+// SymbolScopeString retrieves an enum value from the enum constants string name.
+// Throws an error if the param is not part of the enum.
+func SymbolScopeString(s string) (SymbolScope, error) {
+	if val, ok := _SymbolScopeNameToValueMap[s]; ok {
+		return val, nil
+	}
+
+	if val, ok := _SymbolScopeNameToValueMap[strings.ToLower(s)]; ok {
+		return val, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to SymbolScope values", s)
+}
+
+// SymbolScopeValues returns all values of the enum
+func SymbolScopeValues() []SymbolScope {
+	return _SymbolScopeValues
+}
+
+// SymbolScopeStrings returns a slice of all String values of the enum
+func SymbolScopeStrings() []string {
+	strs := make([]string, len(_SymbolScopeNames))
+	copy(strs, _SymbolScopeNames)
+	return strs
+}
+
+// IsASymbolScope returns "true" if the value is listed in the enum definition. "false" otherwise
+func (i SymbolScope) IsASymbolScope() bool {
+	for _, v := range _SymbolScopeValues {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+//go:generate enumer -type=Severity -json -transform=snake
+type Severity uint8
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+// Code identifies a kind of diagnostic, so that tools can match on it
+// without having to parse the message.
+type Code string
+
+const (
+	// Lexer
+	IllegalCharacter    Code = "E0101"
+	MalformedNumber     Code = "E0102"
+	UnterminatedString  Code = "E0103"
+	InvalidEscape       Code = "E0104"
+	InvalidUTF8         Code = "E0105"
+	UnterminatedComment Code = "E0106"
+
+	// Parser
+	UnexpectedToken   Code = "E0201"
+	MissingExpression Code = "E0202"
+	InvalidInteger    Code = "E0203"
+	InvalidParameter  Code = "E0204"
+	UnclosedDelimiter Code = "E0205"
+	UnmatchedBrace    Code = "E0206"
+	InvalidString     Code = "E0207"
+	InvalidFloat      Code = "E0208"
+	NestedTooDeeply   Code = "E0209"
+
+	// Compiler
+	UndefinedIdentifier  Code = "E0301"
+	TooManyConstants     Code = "E0302"
+	TooManyGlobals       Code = "E0303"
+	TooManyLocals        Code = "E0304"
+	TooManyFreeVariables Code = "E0305"
+	TooManyArguments     Code = "E0306"
+	FunctionTooLarge     Code = "E0307"
+)
+
+type Diagnostic struct {
+	Severity Severity   `json:"severity"`
+	Code     Code       `json:"code,omitempty"`
+	Message  string     `json:"message"`
+	Span     token.Span `json:"span"`
+
+	// Expected and Actual are only set when the problem is that the wrong kind
+	// of token was found.
+	Expected []token.TokenType `json:"expected,omitempty"`
+	Actual   token.TokenType   `json:"actual,omitempty"`
+
+	// Hint is an optional suggestion on how to fix the problem.
+	Hint string `json:"hint,omitempty"`
+}
+
+// Errorf builds an error diagnostic.
+func Errorf(code Code, span token.Span, format string, a ...any) Diagnostic {
+	return Diagnostic{
+		Severity: Error,
+		Code:     code,
+		Message:  fmt.Sprintf(format, a...),
+		Span:     span,
+	}
+}
+
+// Error formats the diagnostic on a single line, ie "file:1:5: error[E0201]: message".
+func (d Diagnostic) Error() string {
+	var sb strings.Builder
+	sb.WriteString(d.Span.String())
+	sb.WriteString(": ")
+	sb.WriteString(d.header())
+	return sb.String()
+}
+
+func (d Diagnostic) header() string {
+	if len(d.Code) == 0 {
+		return d.Severity.String() + ": " + d.Message
+	}
+	return fmt.Sprintf("%s[%s]: %s", d.Severity, d.Code, d.Message)
+}
+
+// HasErrors reports if any of the diagnostics has Error severity.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort orders diagnostics by where they start in the source. Diagnostics
+// that start at the same place keep their relative order.
+func Sort(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diags[i].Span.Start.Offset < diags[j].Span.Start.Offset
+	})
+}
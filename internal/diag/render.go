@@ -0,0 +1,152 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Render writes each diagnostic in a multi-line form that includes the
+// offending line of source, with the problem underlined, ie:
+//
+//	error[E0201]: expected next token to be ident, got assign instead
+//	 --> test.hai:1:5
+//	  |
+//	1 | let = 5;
+//	  |     ^
+func Render(w io.Writer, src string, diags ...Diagnostic) {
+	Renderer{}.Render(w, src, diags...)
+}
+
+// Renderer controls the presentation of diagnostics. The zero value renders
+// plain text.
+type Renderer struct {
+	// Color adds ANSI colors to the header, gutter and underline.
+	Color bool
+
+	// Highlight, if set, is called with the source, and returns it split into
+	// lines, with syntax highlighting added. These lines are shown in place of
+	// the plain source lines.
+	Highlight func(src string) []string
+}
+
+// ANSI escape sequences, used if Color is set
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[1;31m"
+	ansiYellow = "\x1b[1;33m"
+	ansiBlue   = "\x1b[1;34m"
+	ansiCyan   = "\x1b[1;36m"
+)
+
+// Render writes each diagnostic in the same form as the Render function.
+func (r Renderer) Render(w io.Writer, src string, diags ...Diagnostic) {
+	var highlighted []string
+	if r.Highlight != nil && len(diags) > 0 {
+		highlighted = r.Highlight(src)
+	}
+	for _, d := range diags {
+		r.render(w, src, highlighted, d)
+	}
+}
+
+// paint wraps s in the given color, if color is enabled.
+func (r Renderer) paint(color, s string) string {
+	if !r.Color {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+func (r Renderer) header(d Diagnostic) string {
+	if !r.Color {
+		return d.header()
+	}
+	color := ansiRed
+	switch d.Severity {
+	case Warning:
+		color = ansiYellow
+	case Note:
+		color = ansiCyan
+	}
+	label := d.Severity.String()
+	if len(d.Code) > 0 {
+		label = fmt.Sprintf("%s[%s]", d.Severity, d.Code)
+	}
+	return r.paint(color, label) + r.paint(ansiBold, ": "+d.Message)
+}
+
+func (r Renderer) underlineColor(d Diagnostic) string {
+	switch d.Severity {
+	case Warning:
+		return ansiYellow
+	case Note:
+		return ansiCyan
+	default:
+		return ansiRed
+	}
+}
+
+func (r Renderer) render(w io.Writer, src string, highlighted []string, d Diagnostic) {
+	fmt.Fprintln(w, r.header(d))
+
+	start := d.Span.Start
+	if !start.IsValid() || start.Offset > len(src) {
+		if len(d.Hint) > 0 {
+			fmt.Fprintf(w, "  %s %s\n", r.paint(ansiBlue, "="), r.paint(ansiBold, "hint:")+" "+d.Hint)
+		}
+		return
+	}
+
+	lineNum := strconv.Itoa(start.Line)
+	gutter := strings.Repeat(" ", len(lineNum))
+	bar := r.paint(ansiBlue, "|")
+
+	fmt.Fprintf(w, "%s%s %s\n", gutter, r.paint(ansiBlue, "-->"), d.Span)
+	fmt.Fprintf(w, "%s %s\n", gutter, bar)
+
+	lineStart := strings.LastIndexByte(src[:start.Offset], '\n') + 1
+	lineEnd := strings.IndexByte(src[start.Offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(src)
+	} else {
+		lineEnd += start.Offset
+	}
+	line := strings.TrimSuffix(src[lineStart:lineEnd], "\r")
+	shown := line
+	if start.Line <= len(highlighted) {
+		shown = strings.TrimSuffix(highlighted[start.Line-1], "\r")
+	}
+	fmt.Fprintf(w, "%s %s %s\n", r.paint(ansiBlue, lineNum), bar, shown)
+
+	// underline from the start of the span to its end, or to the end of the line
+	// if the span covers more than one line
+	end := d.Span.End.Offset
+	if end > lineStart+len(line) {
+		end = lineStart + len(line)
+	}
+	prefix := line[:min(start.Offset-lineStart, len(line))]
+	width := max(len([]rune(src[min(start.Offset, end):end])), 1)
+	underline := r.paint(r.underlineColor(d), strings.Repeat("^", width))
+	fmt.Fprintf(w, "%s %s %s%s\n", gutter, bar, padding(prefix), underline)
+
+	if len(d.Hint) > 0 {
+		fmt.Fprintf(w, "%s %s %s\n", gutter, r.paint(ansiBlue, "="), r.paint(ansiBold, "hint:")+" "+d.Hint)
+	}
+}
+
+// padding returns whitespace that takes up the same space as s, keeping any
+// tabs so that the underline stays aligned with the source line above it
+func padding(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,148 @@
+package diag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		diag     Diagnostic
+		expected string
+	}{
+		{
+			"single char",
+			"let = 5;",
+			Diagnostic{
+				Code:    UnexpectedToken,
+				Message: "expected next token to be ident, got assign instead",
+				Span:    span("test.hai", 4, 1, 5, 5, 1, 6),
+			},
+			`error[E0201]: expected next token to be ident, got assign instead
+ --> test.hai:1:5
+  |
+1 | let = 5;
+  |     ^
+`,
+		},
+		{
+			"multi char on later line with tabs and hint",
+			"let x = 5;\n\tlet y 10;",
+			Diagnostic{
+				Code:    UnexpectedToken,
+				Message: "expected next token to be assign, got int instead",
+				Span:    span("", 18, 2, 8, 20, 2, 10),
+				Hint:    "let statements look like: let <ident> = <expression>;",
+			},
+			"error[E0201]: expected next token to be assign, got int instead\n" +
+				" --> 2:8\n" +
+				"  |\n" +
+				"2 | \tlet y 10;\n" +
+				"  | \t      ^^\n" +
+				"  = hint: let statements look like: let <ident> = <expression>;\n",
+		},
+		{
+			"non-ascii before the span",
+			"let café = 変数 5;",
+			Diagnostic{
+				Code:    UnexpectedToken,
+				Message: "expected next token to be semicolon, got int instead",
+				Span:    span("", 19, 1, 15, 20, 1, 16),
+			},
+			`error[E0201]: expected next token to be semicolon, got int instead
+ --> 1:15
+  |
+1 | let café = 変数 5;
+  |               ^
+`,
+		},
+		{
+			"at eof",
+			"let x = 5",
+			Diagnostic{
+				Severity: Warning,
+				Message:  "missing semicolon",
+				Span:     span("", 9, 1, 10, 9, 1, 10),
+			},
+			`warning: missing semicolon
+ --> 1:10
+  |
+1 | let x = 5
+  |          ^
+`,
+		},
+		{
+			"span covers multiple lines",
+			"if (x) {\n}\n",
+			Diagnostic{
+				Code:    UnexpectedToken,
+				Message: "oops",
+				Span:    span("", 7, 1, 8, 10, 2, 2),
+			},
+			`error[E0201]: oops
+ --> 1:8
+  |
+1 | if (x) {
+  |        ^
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			Render(&sb, tc.src, tc.diag)
+			if sb.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestRendererColor(t *testing.T) {
+	d := Diagnostic{
+		Code:    UnexpectedToken,
+		Message: "oops",
+		Span:    span("", 15, 2, 5, 16, 2, 6),
+		Hint:    "try again",
+	}
+	r := Renderer{
+		Color: true,
+		Highlight: func(src string) []string {
+			return strings.Split(strings.ToUpper(src), "\n")
+		},
+	}
+
+	expected := "\x1b[1;31merror[E0201]\x1b[0m\x1b[1m: oops\x1b[0m\n" +
+		" \x1b[1;34m-->\x1b[0m 2:5\n" +
+		"  \x1b[1;34m|\x1b[0m\n" +
+		"\x1b[1;34m2\x1b[0m \x1b[1;34m|\x1b[0m LET = 5;\n" +
+		"  \x1b[1;34m|\x1b[0m     \x1b[1;31m^\x1b[0m\n" +
+		"  \x1b[1;34m=\x1b[0m \x1b[1mhint:\x1b[0m try again\n"
+
+	var sb strings.Builder
+	r.Render(&sb, "let x = 1;\nlet = 5;", d)
+	if sb.String() != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, sb.String())
+	}
+}
+
+func TestError(t *testing.T) {
+	d := Errorf(IllegalCharacter, span("a.hai", 3, 1, 4, 4, 1, 5), "illegal character '%c'", '#')
+	expected := "a.hai:1:4: error[E0101]: illegal character '#'"
+	if d.Error() != expected {
+		t.Errorf("expected '%s', got '%s'", expected, d.Error())
+	}
+}
+
+func span(file string, startOffset, startLine, startCol, endOffset, endLine, endCol int) token.Span {
+	return token.Span{
+		File:  file,
+		Start: token.Pos{Offset: startOffset, Line: startLine, Column: startCol},
+		End:   token.Pos{Offset: endOffset, Line: endLine, Column: endCol},
+	}
+}
@@ -0,0 +1,100 @@
+// Code generated by "enumer -type=Severity -json -transform=snake"; DO NOT EDIT.
+
+package diag
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const _SeverityName = "errorwarningnote"
+
+var _SeverityIndex = [...]uint8{0, 5, 12, 16}
+
+const _SeverityLowerName = "errorwarningnote"
+
+func (i Severity) String() string {
+	if i >= Severity(len(_SeverityIndex)-1) {
+		return fmt.Sprintf("Severity(%d)", i)
+	}
+	return _SeverityName[_SeverityIndex[i]:_SeverityIndex[i+1]]
+}
+
+// An "invalid array index" compiler error signifies that the constant values have changed.
+// Re-run the stringer command to generate them again.
+func _SeverityNoOp() {
+	var x [1]struct{}
+	_ = x[Error-(0)]
+	_ = x[Warning-(1)]
+	_ = x[Note-(2)]
+}
+
+var _SeverityValues = []Severity{Error, Warning, Note}
+
+var _SeverityNameToValueMap = map[string]Severity{
+	_SeverityName[0:5]:        Error,
+	_SeverityLowerName[0:5]:   Error,
+	_SeverityName[5:12]:       Warning,
+	_SeverityLowerName[5:12]:  Warning,
+	_SeverityName[12:16]:      Note,
+	_SeverityLowerName[12:16]: Note,
+}
+
+var _SeverityNames = []string{
+	_SeverityName[0:5],
+	_SeverityName[5:12],
+	_SeverityName[12:16],
+}
+
+// SeverityString retrieves an enum value from the enum constants string name.
+// Throws an error if the param is not part of the enum.
+func SeverityString(s string) (Severity, error) {
+	if val, ok := _SeverityNameToValueMap[s]; ok {
+		return val, nil
+	}
+
+	if val, ok := _SeverityNameToValueMap[strings.ToLower(s)]; ok {
+		return val, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Severity values", s)
+}
+
+// SeverityValues returns all values of the enum
+func SeverityValues() []Severity {
+	return _SeverityValues
+}
+
+// SeverityStrings returns a slice of all String values of the enum
+func SeverityStrings() []string {
+	strs := make([]string, len(_SeverityNames))
+	copy(strs, _SeverityNames)
+	return strs
+}
+
+// IsASeverity returns "true" if the value is listed in the enum definition. "false" otherwise
+func (i Severity) IsASeverity() bool {
+	for _, v := range _SeverityValues {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON implements the json.Marshaler interface for Severity
+func (i Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Severity
+func (i *Severity) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Severity should be a string, got %s", data)
+	}
+
+	var err error
+	*i, err = SeverityString(s)
+	return err
+}
@@ -0,0 +1,175 @@
+// Package diff compares texts line by line, and shows the differences as a
+// unified diff.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Context is the number of unchanged lines shown around each change.
+const Context = 3
+
+type op int
+
+const (
+	equal op = iota
+	remove
+	insert
+)
+
+type edit struct {
+	op   op
+	line string // including its newline, if it has one
+}
+
+// Unified returns the changes needed to turn old into new as a unified diff,
+// with the given names in its header, or "" if old and new are the same.
+func Unified(oldName, newName, old, new string) string {
+	if old == new {
+		return ""
+	}
+	es := edits(splitLines(old), splitLines(new))
+
+	// line numbers, counting from 0, at the start of each edit
+	oldLines := make([]int, len(es)+1)
+	newLines := make([]int, len(es)+1)
+	for i, e := range es {
+		oldLines[i+1] = oldLines[i]
+		newLines[i+1] = newLines[i]
+		if e.op != insert {
+			oldLines[i+1]++
+		}
+		if e.op != remove {
+			newLines[i+1]++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	prevStop := 0
+	for i := 0; i < len(es); {
+		for i < len(es) && es[i].op == equal {
+			i++
+		}
+		if i == len(es) {
+			break
+		}
+
+		// extend the hunk over changes whose context would overlap
+		start := max(i-Context, prevStop)
+		end := i
+		for {
+			for end < len(es) && es[end].op != equal {
+				end++
+			}
+			next := end
+			for next < len(es) && es[next].op == equal {
+				next++
+			}
+			if next == len(es) || next-end > 2*Context {
+				break
+			}
+			end = next
+		}
+		stop := min(end+Context, len(es))
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(oldLines[start], oldLines[stop]-oldLines[start]),
+			hunkRange(newLines[start], newLines[stop]-newLines[start]))
+		for _, e := range es[start:stop] {
+			sb.WriteString([]string{" ", "-", "+"}[e.op])
+			sb.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i, prevStop = stop, stop
+	}
+	return sb.String()
+}
+
+// hunkRange formats the lines covered by one side of a hunk. An empty range
+// is given by the line before it.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edits returns a shortest list of edits that turns a into b, using the
+// algorithm from Myers' "An O(ND) Difference Algorithm and Its Variations".
+func edits(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1) // furthest x reached on each diagonal k = x - y
+	var trace [][]int            // v before each step
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // down, inserting from b
+			} else {
+				x = v[offset+k-1] + 1 // right, removing from a
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// walk back from the end to find the path taken
+	var es []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			es = append(es, edit{equal, a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				es = append(es, edit{insert, b[prevY]})
+			} else {
+				es = append(es, edit{remove, a[prevX]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(es)-1; i < j; i, j = i+1, j-1 {
+		es[i], es[j] = es[j], es[i]
+	}
+	return es
+}
@@ -0,0 +1,77 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	cases := []struct {
+		name     string
+		old, new string
+		expected string
+	}{
+		{"same", "a\nb\n", "a\nb\n", ""},
+		{"change", "a\nb\nc\n", "a\nB\nc\n", "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"from empty", "", "a\n", "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n"},
+		{"to empty", "a\nb\n", "", "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		{"no newline", "a\nb", "a\nb\n", "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n"},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			"--- old\n+++ new\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+		{
+			"joined hunks",
+			"1\n2\n3\n4\n5\n6\n7\n",
+			"0\n1\n2\n3\n4\n5\n6\n",
+			"--- old\n+++ new\n@@ -1,7 +1,7 @@\n+0\n 1\n 2\n 3\n 4\n 5\n 6\n-7\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := Unified("old", "new", tc.old, tc.new)
+			if actual != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEdits(t *testing.T) {
+	a := []string{"a", "b", "c", "a", "b", "b", "a"}
+	b := []string{"c", "b", "a", "b", "a", "c"}
+	es := edits(a, b)
+
+	// a shortest edit script for these has 5 insertions and removals
+	var changes int
+	var gotA, gotB []string
+	for _, e := range es {
+		if e.op != equal {
+			changes++
+		}
+		if e.op != insert {
+			gotA = append(gotA, e.line)
+		}
+		if e.op != remove {
+			gotB = append(gotB, e.line)
+		}
+	}
+	if changes != 5 {
+		t.Errorf("expected 5 changes, got %d", changes)
+	}
+	if !equalLines(gotA, a) || !equalLines(gotB, b) {
+		t.Errorf("edits do not rebuild the inputs: %v, %v", gotA, gotB)
+	}
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
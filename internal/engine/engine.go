@@ -0,0 +1,245 @@
+// Package engine runs parsed Hai programs on either the bytecode vm or the
+// tree-walking evaluator, behind a common interface.
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/compiler"
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/evaluator"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/vm"
+)
+
+// Engine executes programs. Global bindings persist from one call to Run to
+// the next.
+type Engine interface {
+	// Run executes program and returns the value it returned, or else the
+	// value of its final statement, or nil if that statement does not
+	// produce a value. Both engines check for undefined identifiers before
+	// the program starts running, and return them as diag.Diagnostic values;
+	// problems found while it runs are returned as *RuntimeError.
+	Run(program *ast.Program) (object.Object, error)
+
+	// Define binds name to val as a global. It fails if name is new and
+	// there is no room for another global.
+	Define(name string, val object.Object) error
+
+	// Get returns the value of the named global.
+	Get(name string) (object.Object, bool)
+
+	// Names returns the names of all globals, in sorted order.
+	Names() []string
+}
+
+// BytecodeCompiler is implemented by engines that run bytecode.
+type BytecodeCompiler interface {
+	// Compile compiles program against the current globals, without running
+	// it or changing any state.
+	Compile(program *ast.Program) (*compiler.Bytecode, error)
+}
+
+// RuntimeError is a problem that stopped a program while it was running.
+type RuntimeError struct {
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Message
+}
+
+// Names lists the engines that can be passed to New.
+var Names = []string{"vm", "eval"}
+
+// Option configures an engine.
+type Option func(*config)
+
+type config struct {
+	out io.Writer
+}
+
+// WithOutput sets where programs write to with puts. The default is
+// os.Stdout.
+func WithOutput(w io.Writer) Option {
+	return func(c *config) {
+		c.out = w
+	}
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{out: os.Stdout}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// New returns a new engine of the named kind.
+func New(name string, opts ...Option) (Engine, error) {
+	switch name {
+	case "vm":
+		return NewVM(opts...), nil
+	case "eval":
+		return NewEvaluator(opts...), nil
+	default:
+		return nil, fmt.Errorf("unknown engine '%s'", name)
+	}
+}
+
+// NewVM returns an engine that compiles programs to bytecode and runs them on
+// the vm.
+func NewVM(opts ...Option) Engine {
+	return &vmEngine{
+		config:    newConfig(opts),
+		symbols:   compiler.NewSymbolTable(),
+		constants: []object.Object{},
+		globals:   make([]object.Object, vm.GlobalsSize),
+	}
+}
+
+type vmEngine struct {
+	config
+	symbols   *compiler.SymbolTable
+	constants []object.Object
+	globals   []object.Object
+}
+
+func (e *vmEngine) Compile(program *ast.Program) (*compiler.Bytecode, error) {
+	bytecode, _, err := e.compile(program)
+	return bytecode, err
+}
+
+// compile compiles program without changing e, and returns the symbols that
+// are defined if the compiled program is run.
+func (e *vmEngine) compile(program *ast.Program) (*compiler.Bytecode, *compiler.SymbolTable, error) {
+	symbols := e.symbols.Clone()
+	// clip the constants so the compiler can't append into our backing array
+	constants := e.constants[:len(e.constants):len(e.constants)]
+
+	comp := compiler.NewWithState(symbols, constants)
+	if err := comp.Compile(program); err != nil {
+		return nil, nil, err
+	}
+	return comp.Bytecode(), symbols, nil
+}
+
+func (e *vmEngine) Run(program *ast.Program) (object.Object, error) {
+	bytecode, symbols, err := e.compile(program)
+	if err != nil {
+		return nil, err
+	}
+	// names defined by the program stay defined even if it fails part way,
+	// in which case the vm reports their use before they are set
+	e.symbols = symbols
+	e.constants = bytecode.Constants
+
+	machine := vm.NewWithGlobalsStore(bytecode, e.globals)
+	machine.SetOutput(e.out)
+	if err := machine.Run(); err != nil {
+		return nil, &RuntimeError{Message: err.Error()}
+	}
+
+	if !machine.Returned() && !producesValue(program) {
+		return nil, nil
+	}
+	return machine.LastPoppedStackElem(), nil
+}
+
+func (e *vmEngine) Define(name string, val object.Object) error {
+	symbol, ok := e.symbols.Resolve(name)
+	if !ok || symbol.Scope != compiler.GlobalScope {
+		if e.symbols.NumDefinitions() >= compiler.MaxGlobals {
+			return fmt.Errorf("cannot define %s: too many global variables (the most is %d)",
+				name, compiler.MaxGlobals)
+		}
+		symbol = e.symbols.Define(name)
+	}
+	e.globals[symbol.Index] = val
+	return nil
+}
+
+func (e *vmEngine) Get(name string) (object.Object, bool) {
+	symbol, ok := e.symbols.Resolve(name)
+	if !ok || symbol.Scope != compiler.GlobalScope || e.globals[symbol.Index] == nil {
+		return nil, false
+	}
+	return e.globals[symbol.Index], true
+}
+
+func (e *vmEngine) Names() []string {
+	var names []string
+	for _, name := range e.symbols.Names() {
+		if _, ok := e.Get(name); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// producesValue reports if the final statement of program leaves a value
+// behind, for programs that run to the end. Without this check, the vm would
+// report a stale value left over from an earlier statement.
+func producesValue(program *ast.Program) bool {
+	if len(program.Statements) == 0 {
+		return false
+	}
+	_, isLet := program.Statements[len(program.Statements)-1].(*ast.LetStatement)
+	return !isLet
+}
+
+// NewEvaluator returns an engine that walks the AST directly.
+func NewEvaluator(opts ...Option) Engine {
+	return &evalEngine{config: newConfig(opts), env: object.NewEnvironment()}
+}
+
+type evalEngine struct {
+	config
+	env *object.Environment
+}
+
+func (e *evalEngine) Run(program *ast.Program) (object.Object, error) {
+	if err := e.resolve(program); err != nil {
+		return nil, err
+	}
+
+	// without limits or a context that can be done, there is no error
+	result, _ := evaluator.EvalContext(context.Background(), program, e.env, evaluator.Limits{}, e.out)
+	if err, ok := result.(*object.Error); ok {
+		return nil, &RuntimeError{Message: err.Message}
+	}
+	return result, nil
+}
+
+// resolve returns the first undefined identifier in program, found as the
+// compiler finds it, so that the evaluator rejects the same programs as the
+// vm. The other problems the compiler reports, such as going over its
+// limits, do not apply to the evaluator.
+func (e *evalEngine) resolve(program *ast.Program) error {
+	symbols := compiler.NewSymbolTable()
+	for _, name := range e.env.Names() {
+		symbols.Define(name)
+	}
+	err := compiler.NewWithState(symbols, []object.Object{}).Compile(program)
+	if d, ok := err.(diag.Diagnostic); ok && d.Code == diag.UndefinedIdentifier {
+		return d
+	}
+	return nil
+}
+
+func (e *evalEngine) Define(name string, val object.Object) error {
+	e.env.Set(name, val)
+	return nil
+}
+
+func (e *evalEngine) Get(name string) (object.Object, bool) {
+	return e.env.Get(name)
+}
+
+func (e *evalEngine) Names() []string {
+	return e.env.Names()
+}
@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/compiler"
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/parser"
+)
+
+func TestEnginesAgree(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string // Inspect of the result, "<nil>" for no result, or the error
+	}{
+		{"1 + 2", "3"},
+		{"1 + 2.0", "3.0"},
+		{"1 / 3.0", "0.3333333333333333"},
+		{"0x10 * 1e20", "1.6e+21"},
+		{"9223372036854775807 * 2", "18446744073709551614"},
+		{"99999999999999999999 + 1 == 100000000000000000000", "true"},
+		{"", "<nil>"},
+		{"let a = 1;", "<nil>"},
+		{"1; let a = 2;", "<nil>"},
+		{"let a = 1; a", "1"},
+		{"return 5; 6", "5"},
+		{"return 5; let y = 1;", "5"},
+		{"if (true) { return 5; }; let y = 1;", "5"},
+		{"if (false) { return 5; }; let y = 1;", "<nil>"},
+		{"let a = 1;\na + b", "2:5: error[E0301]: identifier not found: b"},
+		{"let f = fn() { g() }; let g = fn() { 1 }; f()", "1:16: error[E0301]: identifier not found: g"},
+		{"if (false) { nosuch }", "1:14: error[E0301]: identifier not found: nosuch"},
+		{"1 / 0", "division by zero"},
+		{`puts("a")`, "null"},
+		{`len("abc")`, "3"},
+	}
+
+	for _, name := range Names {
+		for _, tc := range cases {
+			t.Run(name+"/"+tc.input, func(t *testing.T) {
+				e, err := New(name, WithOutput(io.Discard))
+				if err != nil {
+					t.Fatal(err)
+				}
+				result, err := e.Run(parse(t, tc.input))
+				actual := "<nil>"
+				switch {
+				case err != nil:
+					actual = err.Error()
+				case result != nil:
+					actual = result.Inspect()
+				}
+				if actual != tc.expected {
+					t.Errorf("expected %s, got %s", tc.expected, actual)
+				}
+			})
+		}
+	}
+}
+
+func TestEngineOutput(t *testing.T) {
+	for _, name := range Names {
+		t.Run(name, func(t *testing.T) {
+			var out strings.Builder
+			e, _ := New(name, WithOutput(&out))
+			if _, err := e.Run(parse(t, `puts("a", 1); puts(2.5)`)); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != "a\n1\n2.5\n" {
+				t.Errorf("expected the output of puts, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestEngineState(t *testing.T) {
+	for _, name := range Names {
+		t.Run(name, func(t *testing.T) {
+			e, _ := New(name)
+			e.Define("argc", &object.Integer{Value: 2})
+
+			if _, err := e.Run(parse(t, "let double = fn(x) { x * 2 };")); err != nil {
+				t.Fatal(err)
+			}
+			result, err := e.Run(parse(t, "double(argc)"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Inspect() != "4" {
+				t.Errorf("expected 4, got %s", result.Inspect())
+			}
+		})
+	}
+}
+
+func TestEngineErrors(t *testing.T) {
+	for _, name := range Names {
+		t.Run(name, func(t *testing.T) {
+			e, _ := New(name)
+
+			_, err := e.Run(parse(t, "1 / 0"))
+			var rerr *RuntimeError
+			if !errors.As(err, &rerr) || rerr.Message != "division by zero" {
+				t.Errorf("expected a division by zero runtime error, got %v", err)
+			}
+
+			_, err = e.Run(parse(t, "let f = fn() { f() }; f();"))
+			if !errors.As(err, &rerr) || rerr.Message != "stack overflow" {
+				t.Errorf("expected a stack overflow runtime error, got %v", err)
+			}
+
+			// a failed definition leaves nothing usable behind
+			e.Run(parse(t, "let x = 1 / 0;"))
+			_, err = e.Run(parse(t, "x"))
+			if err == nil {
+				t.Errorf("expected an error using x")
+			}
+		})
+	}
+
+	// both report undefined names before running anything
+	for _, name := range Names {
+		t.Run(name+"/undefined", func(t *testing.T) {
+			var out strings.Builder
+			e, _ := New(name, WithOutput(&out))
+			_, err := e.Run(parse(t, `puts("side effect"); y`))
+			var d diag.Diagnostic
+			if !errors.As(err, &d) || d.Code != diag.UndefinedIdentifier {
+				t.Errorf("expected an undefined identifier diagnostic, got %v", err)
+			}
+			if out.Len() > 0 {
+				t.Errorf("expected nothing to run, got output %q", out.String())
+			}
+		})
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); len(diags) > 0 {
+		t.Fatalf("unexpected parse error: %s", diags[0].Error())
+	}
+	return program
+}
+
+func TestEngineGlobals(t *testing.T) {
+	for _, name := range Names {
+		t.Run(name, func(t *testing.T) {
+			e, _ := New(name)
+			e.Run(parse(t, "let b = 2; let a = 1;"))
+			e.Run(parse(t, "let c = 1 / 0;"))
+
+			names := e.Names()
+			if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+				t.Errorf("expected [a b], got %v", names)
+			}
+			if val, ok := e.Get("b"); !ok || val.Inspect() != "2" {
+				t.Errorf("expected b = 2, got %v", val)
+			}
+			if _, ok := e.Get("c"); ok {
+				t.Errorf("expected c to be unset")
+			}
+		})
+	}
+}
+
+func TestVMDefineFull(t *testing.T) {
+	e := NewVM()
+	for i := 0; i < compiler.MaxGlobals; i++ {
+		if err := e.Define(fmt.Sprintf("g%d", i), &object.Integer{Value: int64(i)}); err != nil {
+			t.Fatalf("defining global %d: %v", i, err)
+		}
+	}
+	if err := e.Define("extra", &object.Integer{Value: 1}); err == nil {
+		t.Errorf("expected an error once the globals are full")
+	}
+	if err := e.Define("g0", &object.Integer{Value: 7}); err != nil {
+		t.Errorf("expected an existing global to be redefined, got %v", err)
+	}
+	if val, ok := e.Get("g0"); !ok || val.Inspect() != "7" {
+		t.Errorf("expected g0 = 7, got %v", val)
+	}
+}
+
+func TestVMCompile(t *testing.T) {
+	e := NewVM()
+	e.Run(parse(t, "let a = 1;"))
+
+	// a failed compile defines nothing
+	if _, err := e.Run(parse(t, "let b = 2; c")); err == nil {
+		t.Fatalf("expected c to be undefined")
+	}
+	if _, err := e.Run(parse(t, "b")); err == nil {
+		t.Errorf("expected b to be undefined")
+	}
+
+	// neither does Compile
+	bytecode, err := e.(BytecodeCompiler).Compile(parse(t, "let d = a;"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bytecode.Instructions) == 0 {
+		t.Errorf("expected some instructions")
+	}
+	if _, err := e.Run(parse(t, "d")); err == nil {
+		t.Errorf("expected d to be undefined")
+	}
+}
@@ -0,0 +1,417 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/object"
+)
+
+// there only ever needs to be one of each of these
+var (
+	NULL  = &object.Null{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+)
+
+// Eval walks the given node, executing it in env, and returns the resulting
+// value. Runtime problems are returned as *object.Error values. The puts
+// builtin writes to os.Stdout.
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	e := &evaluator{ctx: context.Background(), builtins: object.Builtins}
+	return e.eval(node, env)
+}
+
+// EvalContext is like Eval, but stops early if ctx is done or if any of the
+// limits are exceeded, in which case it returns why: a *LimitExceeded, or the
+// cause of ctx being done. The puts builtin writes to out.
+func EvalContext(ctx context.Context, node ast.Node, env *object.Environment, limits Limits, out io.Writer) (object.Object, error) {
+	e := &evaluator{ctx: ctx, limits: limits, builtins: object.NewBuiltins(out)}
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	result := e.eval(node, env)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return result, nil
+}
+
+func (e *evaluator) eval(node ast.Node, env *object.Environment) object.Object {
+	if err := e.step(); err != nil {
+		return err
+	}
+	if e.nesting >= maxNesting {
+		return newError("stack overflow")
+	}
+	e.nesting++
+	defer func() { e.nesting-- }()
+
+	switch node := node.(type) {
+
+	// Statements
+
+	case *ast.Program:
+		return e.evalProgram(node, env)
+
+	case *ast.ExpressionStatement:
+		return e.eval(node.Expression, env)
+
+	case *ast.BlockStatement:
+		return e.evalBlockStatement(node, env)
+
+	case *ast.LetStatement:
+		val := e.eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		env.Set(node.Name.Value, val)
+		return nil
+
+	case *ast.ReturnStatement:
+		val := e.eval(node.ReturnValue, env)
+		if isError(val) {
+			return val
+		}
+		return e.alloc(&object.ReturnValue{Value: val})
+
+	// Expressions
+
+	case *ast.IntegerLiteral:
+		return e.alloc(&object.Integer{Value: node.Value, Big: node.Big})
+
+	case *ast.FloatLiteral:
+		return e.alloc(&object.Float{Value: node.Value})
+
+	case *ast.StringLiteral:
+		return e.alloc(&object.String{Value: node.Value})
+
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+
+	case *ast.Identifier:
+		return e.evalIdentifier(node, env)
+
+	case *ast.PrefixExpression:
+		right := e.eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return e.alloc(evalPrefixExpression(node.Operator, right))
+
+	case *ast.InfixExpression:
+		left := e.eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		right := e.eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return e.alloc(evalInfixExpression(node.Operator, left, right))
+
+	case *ast.IfExpression:
+		return e.evalIfExpression(node, env)
+
+	case *ast.FunctionLiteral:
+		return e.alloc(&object.Function{Parameters: node.Parameters, Body: node.Body, Env: env})
+
+	case *ast.CallExpression:
+		function := e.eval(node.Function, env)
+		if isError(function) {
+			return function
+		}
+		args := e.evalExpressions(node.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+		return e.applyFunction(function, args)
+	}
+
+	return newError("unhandled node type: %T", node)
+}
+
+func (e *evaluator) evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, stmt := range program.Statements {
+		result = e.eval(stmt, env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *object.Error:
+			return result
+		}
+	}
+
+	return result
+}
+
+// evalBlockStatement differs from evalProgram in that it does not unwrap
+// return values, so that they can bubble up through nested blocks
+func (e *evaluator) evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, stmt := range block.Statements {
+		result = e.eval(stmt, env)
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE || rt == object.ERROR {
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+func (e *evaluator) evalExpressions(exprs []ast.Expression, env *object.Environment) []object.Object {
+	result := make([]object.Object, 0, len(exprs))
+
+	for _, expr := range exprs {
+		evaluated := e.eval(expr, env)
+		if isError(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+func (e *evaluator) evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+	if builtin := object.GetBuiltinByName(e.builtins, node.Value); builtin != nil {
+		return builtin
+	}
+	return newError("identifier not found: %s", node.Value)
+}
+
+func evalPrefixExpression(operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return evalBangOperatorExpression(right)
+	case "-":
+		return evalMinusPrefixOperatorExpression(right)
+	default:
+		return newError("unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+func evalBangOperatorExpression(right object.Object) object.Object {
+	return nativeBoolToBooleanObject(!isTruthy(right))
+}
+
+func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return right.Neg()
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
+		return newError("unknown operator: -%s", right.Type())
+	}
+}
+
+func evalInfixExpression(operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER && right.Type() == object.INTEGER:
+		return evalIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		// at least one is a float, so both are treated as floats
+		return evalFloatInfixExpression(operator, left, right)
+	case left.Type() == object.STRING && right.Type() == object.STRING:
+		return evalStringInfixExpression(operator, left, right)
+	case left.Type() != right.Type():
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	case operator == "==":
+		// all other types are compared by identity
+		return nativeBoolToBooleanObject(left == right)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(left != right)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Integer)
+	rightVal := right.(*object.Integer)
+
+	switch operator {
+	case "+":
+		return leftVal.Add(rightVal)
+	case "-":
+		return leftVal.Sub(rightVal)
+	case "*":
+		return leftVal.Mul(rightVal)
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return leftVal.Quo(rightVal)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal, _ := object.AsFloat(left)
+	rightVal, _ := object.AsFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func (e *evaluator) evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := e.eval(ie.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	var result object.Object
+	switch {
+	case isTruthy(condition):
+		result = e.eval(ie.Consequence, env)
+	case ie.Alternative != nil:
+		result = e.eval(ie.Alternative, env)
+	}
+
+	if result == nil {
+		// the branch was missing, empty, or ended in a let statement
+		return NULL
+	}
+	return result
+}
+
+func (e *evaluator) applyFunction(fn object.Object, args []object.Object) object.Object {
+	switch fn := fn.(type) {
+	case *object.Function:
+		return e.applyUserFunction(fn, args)
+	case *object.Builtin:
+		if result := fn.Fn(args...); result != nil {
+			return e.alloc(result)
+		}
+		return NULL
+	default:
+		return newError("not a function: %s", fn.Type())
+	}
+}
+
+func (e *evaluator) applyUserFunction(function *object.Function, args []object.Object) object.Object {
+	if len(args) != len(function.Parameters) {
+		return newError("wrong number of arguments: want=%d, got=%d",
+			len(function.Parameters), len(args))
+	}
+
+	if err := e.enter(); err != nil {
+		return err
+	}
+	defer e.leave()
+
+	env := object.NewEnclosedEnvironment(function.Env)
+	for i, param := range function.Parameters {
+		env.Set(param.Value, args[i])
+	}
+	if err := e.allocEnv(len(function.Parameters)); err != nil {
+		return err
+	}
+
+	evaluated := e.eval(function.Body, env)
+	return unwrapReturnValue(evaluated)
+}
+
+func unwrapReturnValue(obj object.Object) object.Object {
+	if rv, ok := obj.(*object.ReturnValue); ok {
+		return rv.Value
+	}
+	if obj == nil {
+		// a function with an empty body
+		return NULL
+	}
+	return obj
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL, FALSE:
+		return false
+	default:
+		return true
+	}
+}
+
+func newError(format string, a ...any) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+func isNumber(obj object.Object) bool {
+	_, ok := object.AsFloat(obj)
+	return ok
+}
+
+func isError(obj object.Object) bool {
+	return obj != nil && obj.Type() == object.ERROR
+}
@@ -0,0 +1,412 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/parser"
+)
+
+func TestEvalIntegerExpression(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int64
+	}{
+		{"5", 5},
+		{"10", 10},
+		{"-5", -5},
+		{"--10", 10},
+		{"5 + 5 + 5 + 5 - 10", 10},
+		{"2 * 2 * 2 * 2 * 2", 32},
+		{"-50 + 100 + -50", 0},
+		{"5 * 2 + 10", 20},
+		{"5 + 2 * 10", 25},
+		{"20 + 2 * -10", 0},
+		{"50 / 2 * 2 + 10", 60},
+		{"2 * (5 + 10)", 30},
+		{"3 * 3 * 3 + 10", 37},
+		{"3 * (3 * 3) + 10", 37},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			testIntegerObject(t, testEval(t, tc.input), tc.expected)
+		})
+	}
+}
+
+func TestEvalBigIntegerExpression(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"0xffff_ffff_ffff_ffff_ffff", "1208925819614629174706175"},
+		{"100000000000000000000 / 3", "33333333333333333333"},
+		{"100000000000000000000 - 99999999999999999999", "1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			evaluated := testEval(t, tc.input)
+			result, ok := evaluated.(*object.Integer)
+			if !ok {
+				t.Fatalf("expected *object.Integer, got %T (%+v)", evaluated, evaluated)
+			}
+			if result.Inspect() != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, result.Inspect())
+			}
+		})
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5", 1.5},
+		{"1e3", 1000},
+		{"-2.5", -2.5},
+		{"1.5 + 2.25", 3.75},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"7 / 2.0", 3.5},
+		{"7.0 - 2", 5},
+		{"0x10 * 0.5", 8},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			evaluated := testEval(t, tc.input)
+			result, ok := evaluated.(*object.Float)
+			if !ok {
+				t.Fatalf("expected *object.Float, got %T (%+v)", evaluated, evaluated)
+			}
+			if result.Value != tc.expected {
+				t.Errorf("expected %g, got %g", tc.expected, result.Value)
+			}
+		})
+	}
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"1 < 2", true},
+		{"1 == 1.0", true},
+		{"1 != 1.5", true},
+		{"1 < 1.5", true},
+		{"2.5 > 3", false},
+		{"1 > 2", false},
+		{"1 < 1", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"1 == 2", false},
+		{"1 != 2", true},
+		{"true == true", true},
+		{"false == false", true},
+		{"true == false", false},
+		{"true != false", true},
+		{"(1 < 2) == true", true},
+		{"(1 > 2) == true", false},
+		{"!true", false},
+		{"!false", true},
+		{"!5", false},
+		{"!!true", true},
+		{"!!5", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			testBooleanObject(t, testEval(t, tc.input), tc.expected)
+		})
+	}
+}
+
+func TestIfElseExpressions(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected any
+	}{
+		{"if (true) { 10 }", int64(10)},
+		{"if (false) { 10 }", nil},
+		{"if (1) { 10 }", int64(10)},
+		{"if (1 < 2) { 10 }", int64(10)},
+		{"if (1 > 2) { 10 }", nil},
+		{"if (1 > 2) { 10 } else { 20 }", int64(20)},
+		{"if (1 < 2) { 10 } else { 20 }", int64(10)},
+		{"if (true) { }", nil},
+		{"if (true) { let x = 1; }", nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			evaluated := testEval(t, tc.input)
+			if i, ok := tc.expected.(int64); ok {
+				testIntegerObject(t, evaluated, i)
+			} else {
+				testNullObject(t, evaluated)
+			}
+		})
+	}
+}
+
+func TestReturnStatements(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int64
+	}{
+		{"return 10;", 10},
+		{"return 10; 9;", 10},
+		{"return 2 * 5; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
+		{`
+if (10 > 1) {
+  if (10 > 1) {
+    return 10;
+  }
+
+  return 1;
+}`, 10},
+		{`
+let f = fn(x) {
+  return x;
+  x + 10;
+};
+f(10);`, 10},
+		{`
+let f = fn(x) {
+   let result = x + 10;
+   return result;
+   return 10;
+};
+f(10);`, 20},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			testIntegerObject(t, testEval(t, tc.input), tc.expected)
+		})
+	}
+}
+
+func TestErrorHandling(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"5 + true;", "type mismatch: integer + boolean"},
+		{"5 + true; 5;", "type mismatch: integer + boolean"},
+		{"-true", "unknown operator: -boolean"},
+		{"true + false;", "unknown operator: boolean + boolean"},
+		{"5; true + false; 5", "unknown operator: boolean + boolean"},
+		{"if (10 > 1) { true + false; }", "unknown operator: boolean + boolean"},
+		{`
+if (10 > 1) {
+  if (10 > 1) {
+    return true + false;
+  }
+
+  return 1;
+}`, "unknown operator: boolean + boolean"},
+		{"foobar", "identifier not found: foobar"},
+		{"10 / 0", "division by zero"},
+		{"10 / 0.0", "division by zero"},
+		{"100000000000000000000 / 0", "division by zero"},
+		{"1.5 + true", "type mismatch: float + boolean"},
+		{"5(1)", "not a function: integer"},
+		{"fn(x) { x }(1, 2)", "wrong number of arguments: want=1, got=2"},
+		{"let f = fn(x) { x }; f(y)", "identifier not found: y"},
+		{`"Hello" - "World"`, "unknown operator: string - string"},
+		{`"Hello" + 1`, "type mismatch: string + integer"},
+		{`-"a"`, "unknown operator: -string"},
+		{"len(1)", "argument to `len` not supported, got integer"},
+		{`len("one", "two")`, "wrong number of arguments: want=1, got=2"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			evaluated := testEval(t, tc.input)
+
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Fatalf("expected *object.Error, got %T (%+v)", evaluated, evaluated)
+			}
+			if errObj.Message != tc.expected {
+				t.Errorf("expected message '%s', got '%s'", tc.expected, errObj.Message)
+			}
+		})
+	}
+}
+
+func TestStringExpressions(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected any
+	}{
+		{`"Hello World!"`, "Hello World!"},
+		{`"Hello" + " " + "World!"`, "Hello World!"},
+		{`let greet = fn(name) { "hi " + name }; greet("bob")`, "hi bob"},
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" < "b"`, true},
+		{`"b" > "a"`, true},
+		{`"abc" < "abd"`, true},
+		{`("a" + "b") == "ab"`, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			evaluated := testEval(t, tc.input)
+			switch expected := tc.expected.(type) {
+			case string:
+				str, ok := evaluated.(*object.String)
+				if !ok {
+					t.Fatalf("expected *object.String, got %T (%+v)", evaluated, evaluated)
+				}
+				if str.Value != expected {
+					t.Errorf("expected %q, got %q", expected, str.Value)
+				}
+			case bool:
+				testBooleanObject(t, evaluated, expected)
+			}
+		})
+	}
+}
+
+func TestLetStatements(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int64
+	}{
+		{"let a = 5; a;", 5},
+		{"let a = 5 * 5; a;", 25},
+		{"let a = 5; let b = a; b;", 5},
+		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			testIntegerObject(t, testEval(t, tc.input), tc.expected)
+		})
+	}
+}
+
+func TestFunctionObject(t *testing.T) {
+	evaluated := testEval(t, "fn(x) { x + 2; };")
+
+	fn, ok := evaluated.(*object.Function)
+	if !ok {
+		t.Fatalf("expected *object.Function, got %T (%+v)", evaluated, evaluated)
+	}
+	if len(fn.Parameters) != 1 {
+		t.Fatalf("expected 1 parameter, got %d", len(fn.Parameters))
+	}
+	if fn.Parameters[0].Value != "x" {
+		t.Errorf("expected parameter 'x', got '%s'", fn.Parameters[0].Value)
+	}
+	if fn.Inspect() != "fn(x) { ... }" {
+		t.Errorf("expected Inspect() 'fn(x) { ... }', got '%s'", fn.Inspect())
+	}
+}
+
+func TestFunctionApplication(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected int64
+	}{
+		{"let identity = fn(x) { x; }; identity(5);", 5},
+		{"let identity = fn(x) { return x; }; identity(5);", 5},
+		{"let double = fn(x) { x * 2; }; double(5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"fn(x) { x; }(5)", 5},
+		{"let x = 1; let f = fn(x) { x }; f(2) + x", 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			testIntegerObject(t, testEval(t, tc.input), tc.expected)
+		})
+	}
+}
+
+func TestClosures(t *testing.T) {
+	input := `
+let newAdder = fn(x) {
+  fn(y) { x + y };
+};
+
+let addTwo = newAdder(2);
+addTwo(2);`
+
+	testIntegerObject(t, testEval(t, input), 4)
+}
+
+func TestRecursion(t *testing.T) {
+	input := `
+let fib = fn(n) {
+  if (n < 2) { return n; }
+  fib(n - 1) + fib(n - 2)
+};
+fib(15);`
+
+	testIntegerObject(t, testEval(t, input), 610)
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	testIntegerObject(t, testEval(t, `len("")`), 0)
+	testIntegerObject(t, testEval(t, `len("héllo")`), 5)
+	testIntegerObject(t, testEval(t, `let len = fn(s) { 42 }; len("four")`), 42)
+	testNullObject(t, testEval(t, `puts()`))
+}
+
+func testEval(t *testing.T, input string) object.Object {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); len(diags) > 0 {
+		t.Fatalf("unexpected parse error: %s", diags[0].Error())
+	}
+	return Eval(program, object.NewEnvironment())
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) {
+	t.Helper()
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Fatalf("expected *object.Integer, got %T (%+v)", obj, obj)
+	}
+	if result.Value != expected {
+		t.Errorf("expected %d, got %d", expected, result.Value)
+	}
+}
+
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) {
+	t.Helper()
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Fatalf("expected *object.Boolean, got %T (%+v)", obj, obj)
+	}
+	if result.Value != expected {
+		t.Errorf("expected %t, got %t", expected, result.Value)
+	}
+}
+
+func testNullObject(t *testing.T, obj object.Object) {
+	t.Helper()
+	if obj != NULL {
+		t.Errorf("expected NULL, got %T (%+v)", obj, obj)
+	}
+}
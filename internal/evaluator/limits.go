@@ -0,0 +1,158 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"unsafe"
+
+	"github.com/danbrakeley/hai/internal/object"
+)
+
+// Limits bounds the resources that a program may use while it is evaluated.
+// A limit of zero means there is no limit. Sizes are estimates, and count
+// everything allocated, including what the garbage collector has since freed.
+type Limits struct {
+	Steps   int64 // nodes evaluated
+	Depth   int64 // nested calls of Hai functions
+	Objects int64 // values and scopes allocated
+	Bytes   int64 // approximate size of the values and scopes allocated
+}
+
+// Limit names one of the Limits.
+type Limit string
+
+const (
+	StepLimit   Limit = "step"
+	DepthLimit  Limit = "call depth"
+	ObjectLimit Limit = "object"
+	ByteLimit   Limit = "byte"
+)
+
+// LimitExceeded is returned by EvalContext when a program is stopped for
+// going over one of its Limits.
+type LimitExceeded struct {
+	Limit Limit
+	Max   int64 // the value of the limit
+}
+
+func (e *LimitExceeded) Error() string {
+	return fmt.Sprintf("exceeded the %s limit of %d", e.Limit, e.Max)
+}
+
+// MaxDepth is how deeply calls of Hai functions can nest, whatever the
+// limits. Going deeper is a "stack overflow" runtime error, as it is in the
+// vm, rather than a crash when the Go stack runs out.
+const MaxDepth = 10000
+
+// maxNesting is how deeply eval can recurse, whatever the limits. The parser
+// bounds how deeply expressions nest, but not how deeply calls of functions
+// that are each nested expressions can pile up on the Go stack.
+const maxNesting = 200000
+
+// checkEvery is how many steps go by between checks of the context, which
+// are not free.
+const checkEvery = 256
+
+// evaluator holds the state of one evaluation.
+type evaluator struct {
+	ctx      context.Context
+	limits   Limits
+	builtins []*object.Builtin
+
+	steps   int64
+	depth   int64
+	nesting int64 // of calls to eval
+	objects int64
+	bytes   int64
+
+	// err is why the evaluation was stopped early, if it was. The error
+	// object returned by stop then makes its way up to the top like any
+	// other runtime error.
+	err error
+}
+
+// stop records why the evaluation is stopping, and returns an error object
+// to pass up.
+func (e *evaluator) stop(err error) *object.Error {
+	e.err = err
+	return &object.Error{Message: err.Error()}
+}
+
+func (e *evaluator) step() *object.Error {
+	e.steps++
+	if e.limits.Steps > 0 && e.steps > e.limits.Steps {
+		return e.stop(&LimitExceeded{Limit: StepLimit, Max: e.limits.Steps})
+	}
+	if e.steps%checkEvery == 0 {
+		select {
+		case <-e.ctx.Done():
+			return e.stop(context.Cause(e.ctx))
+		default:
+		}
+	}
+	return nil
+}
+
+// enter is called as a Hai function is called, and leave as it returns.
+func (e *evaluator) enter() *object.Error {
+	if e.limits.Depth > 0 && e.depth >= e.limits.Depth {
+		return e.stop(&LimitExceeded{Limit: DepthLimit, Max: e.limits.Depth})
+	}
+	if e.depth >= MaxDepth {
+		return newError("stack overflow")
+	}
+	e.depth++
+	return nil
+}
+
+func (e *evaluator) leave() {
+	e.depth--
+}
+
+// alloc counts obj against the limits if it was newly allocated, and returns
+// it, or the error that stops the evaluation if a limit is exceeded.
+func (e *evaluator) alloc(obj object.Object) object.Object {
+	var size uintptr
+	switch obj := obj.(type) {
+	case *object.Integer:
+		size = unsafe.Sizeof(*obj)
+		if obj.Big != nil {
+			size += unsafe.Sizeof(*obj.Big) + uintptr(len(obj.Big.Bits()))*unsafe.Sizeof(big.Word(0))
+		}
+	case *object.Float:
+		size = unsafe.Sizeof(*obj)
+	case *object.String:
+		size = unsafe.Sizeof(*obj) + uintptr(len(obj.Value))
+	case *object.Function:
+		size = unsafe.Sizeof(*obj)
+	case *object.ReturnValue:
+		size = unsafe.Sizeof(*obj)
+	default:
+		// singletons, builtins, and errors, which end the program anyway
+		return obj
+	}
+	if err := e.count(size); err != nil {
+		return err
+	}
+	return obj
+}
+
+// allocEnv counts the scope of a call with the given number of parameters
+// against the limits.
+func (e *evaluator) allocEnv(params int) *object.Error {
+	const binding = unsafe.Sizeof("") + unsafe.Sizeof(object.Object(nil))
+	return e.count(unsafe.Sizeof(object.Environment{}) + uintptr(params)*binding)
+}
+
+func (e *evaluator) count(size uintptr) *object.Error {
+	e.objects++
+	e.bytes += int64(size)
+	if e.limits.Objects > 0 && e.objects > e.limits.Objects {
+		return e.stop(&LimitExceeded{Limit: ObjectLimit, Max: e.limits.Objects})
+	}
+	if e.limits.Bytes > 0 && e.bytes > e.limits.Bytes {
+		return e.stop(&LimitExceeded{Limit: ByteLimit, Max: e.limits.Bytes})
+	}
+	return nil
+}
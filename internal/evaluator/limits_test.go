@@ -0,0 +1,114 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/parser"
+)
+
+func TestLimits(t *testing.T) {
+	const recurse = "let f = fn() { f() }; f();"
+	const count = "let count = fn(n) { if (n > 0) { count(n - 1) } else { 0 } };"
+
+	cases := []struct {
+		input    string
+		limits   Limits
+		expected Limit // or "" if the program should finish
+	}{
+		{recurse, Limits{Depth: 100}, DepthLimit},
+		{recurse, Limits{Steps: 1000}, StepLimit},
+		{recurse, Limits{Objects: 1000}, ObjectLimit},
+		{recurse, Limits{Bytes: 10000}, ByteLimit},
+		{count + "count(100)", Limits{Depth: 101}, ""},
+		{count + "count(101)", Limits{Depth: 101}, DepthLimit},
+		{`"a" + "b"`, Limits{Objects: 3}, ""},
+		{`"a" + "b"`, Limits{Objects: 2}, ObjectLimit},
+		{`let s = "0123456789"; s + s + s + s`, Limits{Bytes: 40}, ByteLimit},
+		{"1 < 2", Limits{Objects: 2}, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := evalWithLimits(t, context.Background(), tc.input, tc.limits)
+			if tc.expected == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			var limitErr *LimitExceeded
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("expected a *LimitExceeded, got %#v", err)
+			}
+			if limitErr.Limit != tc.expected {
+				t.Errorf("expected the %s limit, got the %s limit", tc.expected, limitErr.Limit)
+			}
+		})
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	p := parser.New(lexer.New("let f = fn() { f() }; f();"))
+	result := Eval(p.ParseProgram(), object.NewEnvironment())
+	err, ok := result.(*object.Error)
+	if !ok || err.Message != "stack overflow" {
+		t.Fatalf("expected a stack overflow error, got %#v", result)
+	}
+
+	// calls that are each deeply nested expressions
+	p = parser.New(lexer.New("let f = fn() { " + strings.Repeat("-", 5000) + "f() }; f();"))
+	result = Eval(p.ParseProgram(), object.NewEnvironment())
+	err, ok = result.(*object.Error)
+	if !ok || err.Message != "stack overflow" {
+		t.Fatalf("expected a stack overflow error, got %#v", result)
+	}
+}
+
+func TestEvalContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	stop := errors.New("stop")
+
+	env := object.NewEnvironment()
+	env.Set("stop", &object.Builtin{Name: "stop", Fn: func(args ...object.Object) object.Object {
+		cancel(stop)
+		return nil
+	}})
+
+	p := parser.New(lexer.New("let f = fn() { f() }; stop(); f();"))
+	program := p.ParseProgram()
+	result, err := EvalContext(ctx, program, env, Limits{Depth: 10000}, io.Discard)
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the cause of the cancel, got %#v, %#v", result, err)
+	}
+
+	// a context that is already done stops the program before it starts
+	if _, err := EvalContext(ctx, program, object.NewEnvironment(), Limits{}, io.Discard); !errors.Is(err, stop) {
+		t.Fatalf("expected the cause of the cancel, got %#v", err)
+	}
+}
+
+func TestEvalContextRuntimeError(t *testing.T) {
+	result, err := evalWithLimits(t, context.Background(), "1 + true", Limits{Steps: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if e, ok := result.(*object.Error); !ok || e.Message != "type mismatch: integer + boolean" {
+		t.Errorf("expected a runtime error, got %#v", result)
+	}
+}
+
+func evalWithLimits(t *testing.T, ctx context.Context, input string, limits Limits) (object.Object, error) {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); len(diags) > 0 {
+		t.Fatalf("unexpected parse error: %s", diags[0].Error())
+	}
+	return EvalContext(ctx, program, object.NewEnvironment(), limits, io.Discard)
+}
@@ -0,0 +1,45 @@
+// Package format rewrites Hai source code in the one canonical style: one
+// statement per line, blocks indented by printer.Indent, single spaces around
+// infix operators, opening braces on the same line, and a semicolon after
+// every statement that is not an if expression. Comments are kept.
+package format
+
+import (
+	"strings"
+
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/parser"
+	"github.com/danbrakeley/hai/internal/printer"
+)
+
+// Source returns src in the canonical style. If src cannot be parsed, it is
+// left alone, and the problems found are returned instead. The filename is
+// only used in the spans of those problems.
+func Source(filename, src string) (string, []diag.Diagnostic) {
+	p := parser.New(lexer.New(src, lexer.WithFilename(filename), lexer.WithComments()))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); diag.HasErrors(diags) {
+		return "", diags
+	}
+
+	var sb strings.Builder
+	if shebang := shebangLine(src); shebang != "" {
+		// the lexer skips over this line, so it is not part of the program
+		sb.WriteString(shebang)
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(printer.SprintWithComments(program, p.Comments()))
+	return sb.String(), nil
+}
+
+// shebangLine returns the first line of src if it starts with "#!", as
+// lexer.New ignores it.
+func shebangLine(src string) string {
+	src = strings.TrimPrefix(src, "\uFEFF")
+	if !strings.HasPrefix(src, "#!") {
+		return ""
+	}
+	line, _, _ := strings.Cut(src, "\n")
+	return strings.TrimRight(line, " \t\r")
+}
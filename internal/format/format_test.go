@@ -0,0 +1,82 @@
+package format
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the .golden files")
+
+// TestGolden formats each testdata/*.input file and compares the result with
+// the matching .golden file.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			src, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, diags := Source(input, string(src))
+			if len(diags) > 0 {
+				t.Fatalf("unexpected error: %s", diags[0].Error())
+			}
+
+			golden := strings.TrimSuffix(input, ".input") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(actual), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != string(expected) {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+			}
+
+			// formatting again changes nothing
+			again, _ := Source(golden, actual)
+			if again != actual {
+				t.Errorf("formatting is not idempotent, second pass gave:\n%s", again)
+			}
+		})
+	}
+}
+
+// TestIdempotent checks that formatting what fmt wrote changes nothing, for
+// statements with a mix of line and block comments inside them.
+func TestIdempotent(t *testing.T) {
+	inputs := []string{
+		"let x = 1 //a\n+ /*c*/ 2 //b\n+ 3;\n",
+		"let x = 1 /*a*/ + /*b*/ 2; //c\n",
+		"f(1, //a\n/*b*/ 2, /*c*/ 3 //d\n);\n",
+		"if (x) { /*a*/ 1 //b\n/*c*/ } //d\n",
+	}
+	for _, input := range inputs {
+		once, diags := Source("test.hai", input)
+		if len(diags) > 0 {
+			t.Fatalf("%q: unexpected error: %s", input, diags[0].Error())
+		}
+		if twice, _ := Source("test.hai", once); twice != once {
+			t.Errorf("%q: formatting is not idempotent, first pass gave:\n%s\nsecond pass gave:\n%s", input, once, twice)
+		}
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	out, diags := Source("bad.hai", "let x = ;")
+	if out != "" {
+		t.Errorf("expected no output, got %q", out)
+	}
+	if len(diags) == 0 || diags[0].Span.File != "bad.hai" {
+		t.Errorf("expected a diagnostic in bad.hai, got %v", diags)
+	}
+}
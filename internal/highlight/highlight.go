@@ -0,0 +1,160 @@
+// Package highlight adds ANSI colors to Hai source, based on the types of the
+// tokens the lexer finds in it.
+package highlight
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/token"
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences for the colors used
+const (
+	Reset   = "\x1b[0m"
+	Bold    = "\x1b[1m"
+	Red     = "\x1b[31m"
+	Green   = "\x1b[32m"
+	Yellow  = "\x1b[33m"
+	Blue    = "\x1b[34m"
+	Magenta = "\x1b[35m"
+	Cyan    = "\x1b[36m"
+	Gray    = "\x1b[90m"
+)
+
+// Class groups token types that are colored the same way.
+type Class int
+
+const (
+	Plain Class = iota
+	Keyword
+	Number
+	String
+	Constant // true, false and null
+	Operator
+	Comment
+	Illegal
+)
+
+var colors = map[Class]string{
+	Keyword:  Magenta,
+	Number:   Yellow,
+	String:   Green,
+	Constant: Cyan,
+	Operator: Blue,
+	Comment:  Gray,
+	Illegal:  Bold + Red,
+}
+
+// Color returns the escape sequence that starts text of class c, or "" if c
+// is not colored.
+func (c Class) Color() string {
+	return colors[c]
+}
+
+// Wrap returns text colored as class c. Each line is colored separately, so
+// the result can be split into lines without breaking any colors.
+func Wrap(c Class, text string) string {
+	color := c.Color()
+	if color == "" || text == "" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = color + line + Reset
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ClassOf returns the class that tokens of type t belong to.
+func ClassOf(t token.TokenType) Class {
+	switch t {
+	case token.FUNCTION, token.LET, token.IF, token.ELSE, token.RETURN:
+		return Keyword
+	case token.TRUE, token.FALSE:
+		return Constant
+	case token.INT, token.FLOAT:
+		return Number
+	case token.STRING:
+		return String
+	case token.COMMENT:
+		return Comment
+	case token.ILLEGAL:
+		return Illegal
+	case token.ASSIGN, token.PLUS, token.MINUS, token.BANG, token.ASTERISK, token.SLASH,
+		token.LT, token.GT, token.EQ, token.NOT_EQ:
+		return Operator
+	default:
+		return Plain
+	}
+}
+
+// Source returns src with each token colored according to its type. The
+// text between tokens, and any text the lexer skips over (ie a shebang line),
+// is kept as is.
+func Source(src string) string {
+	var sb strings.Builder
+	l := lexer.New(src, lexer.WithComments())
+	last := 0
+	for {
+		tok := l.NextToken()
+		start, end := tok.Span().Start.Offset, tok.Span().End.Offset
+		sb.WriteString(src[last:start])
+		if tok.Is(token.EOF) {
+			break
+		}
+		sb.WriteString(Wrap(ClassOf(tok.Type()), src[start:end]))
+		last = end
+	}
+	return sb.String()
+}
+
+// Lines returns src split into lines, with each line colored by Source.
+func Lines(src string) []string {
+	return strings.Split(Source(src), "\n")
+}
+
+// Value returns the Inspect form of obj, colored like the literal that would
+// produce it.
+func Value(obj object.Object) string {
+	s := obj.Inspect()
+	switch obj.Type() {
+	case object.INTEGER, object.FLOAT:
+		return Wrap(Number, s)
+	case object.STRING:
+		return Wrap(String, s)
+	case object.BOOLEAN, object.NULL:
+		return Wrap(Constant, s)
+	case object.ERROR:
+		return Wrap(Illegal, s)
+	default:
+		return s
+	}
+}
+
+// Enabled reports if colors should be written to w: it must be a terminal,
+// and the NO_COLOR environment variable must not be set (see
+// https://no-color.org).
+func Enabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Renderer returns a renderer for diagnostics that, if color is set, colors
+// the diagnostics and highlights the source lines they show.
+func Renderer(color bool) diag.Renderer {
+	if !color {
+		return diag.Renderer{}
+	}
+	return diag.Renderer{Color: true, Highlight: Lines}
+}
@@ -0,0 +1,109 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+func TestSource(t *testing.T) {
+	cases := []struct {
+		src      string
+		expected string
+	}{
+		{
+			"let x = 10;",
+			Magenta + "let" + Reset + " x " + Blue + "=" + Reset + " " + Yellow + "10" + Reset + ";",
+		},
+		{
+			`if (true) { "a" } // done`,
+			Magenta + "if" + Reset + " (" + Cyan + "true" + Reset + ") { " + Green + `"a"` + Reset + " } " + Gray + "// done" + Reset,
+		},
+		{
+			"x @ 1",
+			"x " + Bold + Red + "@" + Reset + " " + Yellow + "1" + Reset,
+		},
+		{
+			// each line of a multi-line token is colored separately
+			"/* a\nb */ fn",
+			Gray + "/* a" + Reset + "\n" + Gray + "b */" + Reset + " " + Magenta + "fn" + Reset,
+		},
+		{
+			"#!/usr/bin/env hai\n\t1\n",
+			"#!/usr/bin/env hai\n\t" + Yellow + "1" + Reset + "\n",
+		},
+		{`"unterminated`, Bold + Red + `"unterminated` + Reset},
+		{"", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.src, func(t *testing.T) {
+			if actual := Source(tc.src); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSourceKeepsText(t *testing.T) {
+	src := "let s = `raw\nstring`; /* c */ fn(a, b) { a != b }\n\xffé"
+	stripped := strings.NewReplacer(
+		Reset, "", Bold, "", Red, "", Green, "", Yellow, "", Blue, "", Magenta, "", Cyan, "", Gray, "",
+	).Replace(Source(src))
+	if stripped != src {
+		t.Errorf("expected %q, got %q", src, stripped)
+	}
+	if lines := Lines(src); len(lines) != 3 {
+		t.Errorf("expected 3 lines, got %d", len(lines))
+	}
+}
+
+func TestClassOf(t *testing.T) {
+	// every token type is in a class
+	for _, typ := range token.TokenTypeValues() {
+		c := ClassOf(typ)
+		if c < Plain || c > Illegal {
+			t.Errorf("%s has invalid class %d", typ, c)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	if v := Value(&object.Integer{Value: 5}); v != Yellow+"5"+Reset {
+		t.Errorf("unexpected integer %q", v)
+	}
+	if v := Value(&object.String{Value: ""}); v != "" {
+		t.Errorf("unexpected empty string %q", v)
+	}
+	if v := Value(&object.Builtin{Name: "len"}); v != "builtin len" {
+		t.Errorf("unexpected builtin %q", v)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	var sb strings.Builder
+	if Enabled(&sb) {
+		t.Errorf("expected no color for a non-terminal")
+	}
+}
+
+func TestRenderer(t *testing.T) {
+	d := diag.Errorf(diag.UnexpectedToken, token.Span{
+		Start: token.Pos{Offset: 4, Line: 1, Column: 5},
+		End:   token.Pos{Offset: 5, Line: 1, Column: 6},
+	}, "oops")
+
+	var plain, colored strings.Builder
+	Renderer(false).Render(&plain, "let = 5;", d)
+	Renderer(true).Render(&colored, "let = 5;", d)
+
+	if strings.Contains(plain.String(), "\x1b[") {
+		t.Errorf("expected no color, got %q", plain.String())
+	}
+	if !strings.Contains(colored.String(), Magenta+"let"+Reset) {
+		t.Errorf("expected highlighted source, got %q", colored.String())
+	}
+}
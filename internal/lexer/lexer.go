@@ -1,43 +1,158 @@
 package lexer
 
 import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/danbrakeley/hai/internal/diag"
 	"github.com/danbrakeley/hai/internal/token"
 )
 
 type Lexer struct {
 	input        string
+	filename     string
 	position     int  // current reading position in input (points to current char)
 	readPosition int  // current reading position in input (after current char)
-	ch           byte // current char under examination
+	ch           rune // current char under examination
+	line         int  // line of current char, starting at 1
+	column       int  // column of current char, starting at 1, counted in runes
+
+	emitComments bool
+
+	diags   []diag.Diagnostic
+	pending *diag.Diagnostic // problem with the token currently being read
+}
+
+type Option func(*Lexer)
+
+// WithComments makes NextToken return comments as COMMENT tokens, instead of
+// skipping over them.
+func WithComments() Option {
+	return func(l *Lexer) {
+		l.emitComments = true
+	}
+}
+
+// WithFilename sets the file name recorded in the span of each token.
+func WithFilename(filename string) Option {
+	return func(l *Lexer) {
+		l.filename = filename
+	}
 }
 
-func New(input string) *Lexer {
-	l := &Lexer{input: input}
+func New(input string, opts ...Option) *Lexer {
+	l := &Lexer{input: input, line: 1}
+	for _, opt := range opts {
+		opt(l)
+	}
 	l.readChar()
+	if l.ch == '\uFEFF' {
+		// ignore a byte order mark at the start of the input
+		l.readChar()
+		l.column = 1
+	}
+	if l.ch == '#' && l.peekChar() == '!' {
+		// ignore a shebang line, so scripts can be executed directly
+		for l.ch != '\n' && l.ch != 0 {
+			l.readChar()
+		}
+	}
 	return l
 }
 
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
+	if l.readPosition > len(l.input) {
+		// already at EOF
+		return
+	}
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
+	width := 1
+	if l.readPosition == len(l.input) {
 		l.ch = 0
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition += 1
+	l.readPosition += width
+	l.column += 1
 }
 
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
-	return l.input[l.readPosition]
+	r, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return r
+}
+
+// isInvalidChar reports if the current char is not valid UTF-8
+func (l *Lexer) isInvalidChar() bool {
+	return l.ch == utf8.RuneError && l.readPosition-l.position == 1
+}
+
+// failInvalidChar records that the current char is not valid UTF-8
+func (l *Lexer) failInvalidChar() {
+	l.failSpan(diag.InvalidUTF8, l.spanFrom(l.pos(), 1),
+		"invalid UTF-8 encoding (byte 0x%02x)", l.input[l.position])
+}
+
+// pos returns the position of the current char
+func (l *Lexer) pos() token.Pos {
+	return token.Pos{Offset: l.position, Line: l.line, Column: l.column}
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
+	for {
+		l.skipWhitespace()
+
+		start := l.pos()
+		tok := l.readToken()
+		span := token.Span{File: l.filename, Start: start, End: l.pos()}
+
+		if l.pending != nil {
+			if !l.pending.Span.Start.IsValid() {
+				l.pending.Span = span
+			}
+			l.diags = append(l.diags, *l.pending)
+			l.pending = nil
+		}
 
-	l.skipWhitespace()
+		if tok.Is(token.COMMENT) && !l.emitComments {
+			continue
+		}
+
+		return tok.WithSpan(span)
+	}
+}
+
+// Diagnostics returns any problems found in the tokens read so far.
+func (l *Lexer) Diagnostics() []diag.Diagnostic {
+	return l.diags
+}
+
+// fail records a problem with the token currently being read. The span of the
+// diagnostic is filled in once the whole token has been read.
+func (l *Lexer) fail(code diag.Code, format string, a ...any) {
+	l.failSpan(code, token.Span{}, format, a...)
+}
+
+// failSpan records a problem with part of the token currently being read.
+// Only the first problem found in a token is kept.
+func (l *Lexer) failSpan(code diag.Code, span token.Span, format string, a ...any) {
+	if l.pending != nil {
+		return
+	}
+	d := diag.Errorf(code, span, format, a...)
+	l.pending = &d
+}
+
+// readToken reads the token that starts at the current char, and leaves the
+// current char just past the end of that token
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
 
 	switch l.ch {
 	case '=':
@@ -61,7 +176,15 @@ func (l *Lexer) NextToken() token.Token {
 	case '*':
 		tok = token.New(token.ASTERISK, l.ch)
 	case '/':
-		tok = token.New(token.SLASH, l.ch)
+		switch l.peekChar() {
+		case '/':
+			// early out so we don't skip the newline that ended the comment
+			return token.New(token.COMMENT, l.readLineComment())
+		case '*':
+			tok = token.New(token.COMMENT, l.readBlockComment())
+		default:
+			tok = token.New(token.SLASH, l.ch)
+		}
 	case '<':
 		tok = token.New(token.LT, l.ch)
 	case '>':
@@ -78,6 +201,23 @@ func (l *Lexer) NextToken() token.Token {
 		tok = token.New(token.LBRACE, l.ch)
 	case '}':
 		tok = token.New(token.RBRACE, l.ch)
+	case '"', '`':
+		var lit string
+		var ok bool
+		if l.ch == '"' {
+			lit, ok = l.readString()
+		} else {
+			lit, ok = l.readRawString()
+		}
+		if !ok {
+			// early out so we don't skip the newline or EOF that ended the string
+			return token.New(token.ILLEGAL, lit)
+		}
+		if l.pending != nil {
+			tok = token.New(token.ILLEGAL, lit)
+		} else {
+			tok = token.New(token.STRING, lit)
+		}
 	case 0:
 		tok = token.New(token.EOF, "")
 	default:
@@ -86,18 +226,15 @@ func (l *Lexer) NextToken() token.Token {
 			tok = token.NewIdent(l.readIdentifier())
 			// early out so we don't skip the next char
 			return tok
-		case isDigit(l.ch):
-			lit := l.readNumber()
-			if isValidStartToIdent(l.ch) {
-				lit = lit + l.readIdentifier()
-				tok = token.New(token.ILLEGAL, lit)
-			} else {
-				tok = token.New(token.INT, lit)
-			}
+		case isDigit(l.ch), l.ch == '.' && isDigit(l.peekChar()):
 			// early out so we don't skip the next char
-			return tok
+			return l.readNumber()
+		case l.isInvalidChar():
+			tok = token.New(token.ILLEGAL, l.input[l.position:l.readPosition])
+			l.failInvalidChar()
 		default:
 			tok = token.New(token.ILLEGAL, l.ch)
+			l.fail(diag.IllegalCharacter, "illegal character '%c'", l.ch)
 		}
 	}
 	l.readChar()
@@ -120,30 +257,59 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+// readLineComment assumes the current char is the first slash of "//", and
+// leaves the current char on the newline (or EOF) that ends the comment
+func (l *Lexer) readLineComment() string {
 	position := l.position
-	for isDigit(l.ch) {
+	for l.ch != '\n' && l.ch != 0 {
+		if l.isInvalidChar() {
+			l.failInvalidChar()
+		}
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-func isWhitespace(ch byte) bool {
-	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+// readBlockComment assumes the current char is the slash of "/*", and leaves
+// the current char on the slash of the matching "*/". Block comments nest.
+func (l *Lexer) readBlockComment() string {
+	position := l.position
+	depth := 0
+	for {
+		switch {
+		case l.ch == 0:
+			l.fail(diag.UnterminatedComment, "unterminated block comment")
+			return l.input[position:l.position]
+		case l.ch == '/' && l.peekChar() == '*':
+			depth++
+			l.readChar()
+		case l.ch == '*' && l.peekChar() == '/':
+			depth--
+			l.readChar()
+			if depth == 0 {
+				return l.input[position:l.readPosition]
+			}
+		case l.isInvalidChar():
+			l.failInvalidChar()
+		}
+		l.readChar()
+	}
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
+func isWhitespace(ch rune) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
 }
 
-func isDigit(ch byte) bool {
+// isDigit only accepts ASCII digits, as those are the only digits allowed in
+// number literals
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func isValidStartToIdent(ch byte) bool {
-	return isLetter(ch) || ch == '_'
+func isValidStartToIdent(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func isValidBodyOfIdent(ch byte) bool {
-	return isLetter(ch) || ch == '_' || isDigit(ch)
+func isValidBodyOfIdent(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_' || unicode.IsDigit(ch)
 }
@@ -1,9 +1,14 @@
 package lexer
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"testing"
 
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/strlit"
 	"github.com/danbrakeley/hai/internal/token"
 )
 
@@ -22,12 +27,15 @@ func TestNextToken_IndividualTokens(t *testing.T) {
 		{"_a2D", token.IDENT, "_a2D"},
 		{"2a", token.ILLEGAL, "2a"},
 		{"10", token.INT, "10"},
+		{"1.5", token.FLOAT, "1.5"},
+		{`"hi"`, token.STRING, `"hi"`},
 		{"=", token.ASSIGN, "="},
 		{"+", token.PLUS, "+"},
 		{"-", token.MINUS, "-"},
 		{"!", token.BANG, "!"},
 		{"*", token.ASTERISK, "*"},
 		{"/", token.SLASH, "/"},
+		{"// hi", token.COMMENT, "// hi"},
 		{"<", token.LT, "<"},
 		{">", token.GT, ">"},
 		{"==", token.EQ, "=="},
@@ -65,7 +73,7 @@ func TestNextToken_IndividualTokens(t *testing.T) {
 				t.Errorf("invalid token type: %d", tc.expectedType)
 			}
 
-			l := New(tc.token)
+			l := New(tc.token, WithComments())
 			tok := l.NextToken()
 
 			if tok.Type() != tc.expectedType {
@@ -90,7 +98,7 @@ let add = fn(x, y) {
 };
 
 let result = add(five, ten);
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 
 if (5 < 10) {
@@ -198,3 +206,457 @@ if (5 < 10) {
 		}
 	}
 }
+
+func TestNextToken_Spans(t *testing.T) {
+	input := "let x = 5;\n\tx == 10\n"
+
+	tests := []struct {
+		expectedType token.TokenType
+		start        token.Pos
+		end          token.Pos
+	}{
+		{token.LET, token.Pos{Offset: 0, Line: 1, Column: 1}, token.Pos{Offset: 3, Line: 1, Column: 4}},
+		{token.IDENT, token.Pos{Offset: 4, Line: 1, Column: 5}, token.Pos{Offset: 5, Line: 1, Column: 6}},
+		{token.ASSIGN, token.Pos{Offset: 6, Line: 1, Column: 7}, token.Pos{Offset: 7, Line: 1, Column: 8}},
+		{token.INT, token.Pos{Offset: 8, Line: 1, Column: 9}, token.Pos{Offset: 9, Line: 1, Column: 10}},
+		{token.SEMICOLON, token.Pos{Offset: 9, Line: 1, Column: 10}, token.Pos{Offset: 10, Line: 1, Column: 11}},
+		{token.IDENT, token.Pos{Offset: 12, Line: 2, Column: 2}, token.Pos{Offset: 13, Line: 2, Column: 3}},
+		{token.EQ, token.Pos{Offset: 14, Line: 2, Column: 4}, token.Pos{Offset: 16, Line: 2, Column: 6}},
+		{token.INT, token.Pos{Offset: 17, Line: 2, Column: 7}, token.Pos{Offset: 19, Line: 2, Column: 9}},
+		{token.EOF, token.Pos{Offset: 20, Line: 3, Column: 1}, token.Pos{Offset: 20, Line: 3, Column: 1}},
+		{token.EOF, token.Pos{Offset: 20, Line: 3, Column: 1}, token.Pos{Offset: 20, Line: 3, Column: 1}},
+	}
+
+	l := New(input, WithFilename("test.hai"))
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type() != tt.expectedType {
+			t.Fatalf("cases[%d]: expected token type %s, got %s",
+				i, tt.expectedType.String(), tok.Type().String())
+		}
+
+		span := tok.Span()
+		if span.File != "test.hai" {
+			t.Errorf("cases[%d]: expected file 'test.hai', got '%s'", i, span.File)
+		}
+		if span.Start != tt.start {
+			t.Errorf("cases[%d]: expected start %+v, got %+v", i, tt.start, span.Start)
+		}
+		if span.End != tt.end {
+			t.Errorf("cases[%d]: expected end %+v, got %+v", i, tt.end, span.End)
+		}
+		if tok.Pos() != tt.start {
+			t.Errorf("cases[%d]: expected Pos() %+v, got %+v", i, tt.start, tok.Pos())
+		}
+	}
+}
+
+func TestNextToken_Strings(t *testing.T) {
+	cases := []struct {
+		input         string
+		expectedType  token.TokenType
+		expectedValue string
+	}{
+		{`""`, token.STRING, ""},
+		{`"hello world"`, token.STRING, "hello world"},
+		{`"a\nb\tc\rd"`, token.STRING, "a\nb\tc\rd"},
+		{`"say \"hi\""`, token.STRING, `say "hi"`},
+		{`"back\\slash"`, token.STRING, `back\slash`},
+		{`"\u{48}\u{49}"`, token.STRING, "HI"},
+		{`"\u{1F600}"`, token.STRING, "\U0001F600"},
+		{`"héllo"`, token.STRING, "héllo"},
+		{"``", token.STRING, ""},
+		{"`raw\\n`", token.STRING, `raw\n`},
+		{"`multi\nline`", token.STRING, "multi\nline"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			l := New(tc.input)
+			tok := l.NextToken()
+
+			if tok.Type() != tc.expectedType {
+				t.Fatalf("expected token type %s, got %s", tc.expectedType, tok.Type())
+			}
+			if tok.Literal() != tc.input {
+				t.Errorf("expected literal '%s', got '%s'", tc.input, tok.Literal())
+			}
+			if diags := l.Diagnostics(); len(diags) > 0 {
+				t.Errorf("unexpected diagnostic: %s", diags[0].Error())
+			}
+
+			value, err := strlit.Unquote(tok.Literal())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tc.expectedValue {
+				t.Errorf("expected value %q, got %q", tc.expectedValue, value)
+			}
+
+			if next := l.NextToken(); !next.Is(token.EOF) {
+				t.Errorf("expected eof after string, got %s", next.Type())
+			}
+		})
+	}
+}
+
+func TestNextToken_StringErrors(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		code         diag.Code
+		start        int // column
+		end          int // column
+		expectedNext token.TokenType
+	}{
+		{"unterminated at eof", `"abc`, diag.UnterminatedString, 1, 5, token.EOF},
+		{"unterminated at newline", "\"abc\nx", diag.UnterminatedString, 1, 5, token.IDENT},
+		{"unterminated raw", "`abc\n", diag.UnterminatedString, 1, 1, token.EOF},
+		{"unknown escape", `"a\qb" x`, diag.InvalidEscape, 3, 5, token.IDENT},
+		{"bad unicode escape", `"\u0041" x`, diag.InvalidEscape, 2, 4, token.IDENT},
+		{"empty unicode escape", `"\u{}" x`, diag.InvalidEscape, 2, 6, token.IDENT},
+		{"too many hex digits", `"\u{1234567}" x`, diag.InvalidEscape, 2, 11, token.IDENT},
+		{"surrogate", `"\u{D800}" x`, diag.InvalidEscape, 2, 10, token.IDENT},
+		{"out of range", `"\u{110000}" x`, diag.InvalidEscape, 2, 12, token.IDENT},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := New(tc.input)
+			tok := l.NextToken()
+
+			if !tok.Is(token.ILLEGAL) {
+				t.Fatalf("expected illegal token, got %s", tok.Type())
+			}
+
+			diags := l.Diagnostics()
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+			}
+			d := diags[0]
+			if d.Code != tc.code {
+				t.Errorf("expected code %s, got %s", tc.code, d.Code)
+			}
+			if d.Span.Start.Column != tc.start || d.Span.End.Column != tc.end {
+				t.Errorf("expected columns %d-%d, got %d-%d (%s)",
+					tc.start, tc.end, d.Span.Start.Column, d.Span.End.Column, d.Error())
+			}
+
+			if next := l.NextToken(); !next.Is(tc.expectedNext) {
+				t.Errorf("expected next token to be %s, got %s", tc.expectedNext, next.Type())
+			}
+		})
+	}
+}
+
+func TestNextToken_Unicode(t *testing.T) {
+	input := "let café = \"naïve\"; 変数 + x₁ + π2;"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		column          int
+	}{
+		{token.LET, "let", 1},
+		{token.IDENT, "café", 5},
+		{token.ASSIGN, "=", 10},
+		{token.STRING, `"naïve"`, 12},
+		{token.SEMICOLON, ";", 19},
+		{token.IDENT, "変数", 21},
+		{token.PLUS, "+", 24},
+		{token.IDENT, "x", 26}, // subscripts are not letters or digits
+		{token.ILLEGAL, "₁", 27},
+		{token.PLUS, "+", 29},
+		{token.IDENT, "π2", 31},
+		{token.SEMICOLON, ";", 33},
+		{token.EOF, "", 34},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type() != tt.expectedType {
+			t.Fatalf("cases[%d]: expected token type %s, got %s",
+				i, tt.expectedType.String(), tok.Type().String())
+		}
+		if tok.Literal() != tt.expectedLiteral {
+			t.Fatalf("cases[%d]: expected literal '%s', got '%s'",
+				i, tt.expectedLiteral, tok.Literal())
+		}
+		if tok.Pos().Column != tt.column {
+			t.Errorf("cases[%d]: expected column %d, got %d", i, tt.column, tok.Pos().Column)
+		}
+	}
+
+	diags := l.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != diag.IllegalCharacter {
+		t.Errorf("expected a single illegal character diagnostic, got %v", diags)
+	}
+}
+
+func TestNextToken_InvalidUTF8(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		column int
+	}{
+		{"in code", "let x\xff = 1;", 6},
+		{"in string", "\"ab\xc3\" + 1;", 4},
+		{"in raw string", "`ab\x80`", 4},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := New(tc.input)
+			var sawIllegal bool
+			for tok := l.NextToken(); !tok.Is(token.EOF); tok = l.NextToken() {
+				sawIllegal = sawIllegal || tok.Is(token.ILLEGAL)
+			}
+			if !sawIllegal {
+				t.Errorf("expected an illegal token")
+			}
+
+			diags := l.Diagnostics()
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+			}
+			d := diags[0]
+			if d.Code != diag.InvalidUTF8 {
+				t.Errorf("expected code %s, got %s", diag.InvalidUTF8, d.Code)
+			}
+			if d.Span.Start.Column != tc.column || d.Span.Len() != 1 {
+				t.Errorf("expected a 1 byte span at column %d, got %s (len %d)", tc.column, d.Span, d.Span.Len())
+			}
+		})
+	}
+}
+
+func TestNextToken_ByteOrderMark(t *testing.T) {
+	l := New("\uFEFFlet")
+	tok := l.NextToken()
+	if !tok.Is(token.LET) {
+		t.Fatalf("expected let, got %s", tok.Type())
+	}
+	if tok.Pos() != (token.Pos{Offset: 3, Line: 1, Column: 1}) {
+		t.Errorf("unexpected position %+v", tok.Pos())
+	}
+}
+
+func TestNextToken_Shebang(t *testing.T) {
+	l := New("#!/usr/bin/env hai\nlet")
+	tok := l.NextToken()
+	if !tok.Is(token.LET) {
+		t.Fatalf("expected let, got %s", tok.Type())
+	}
+	if tok.Pos() != (token.Pos{Offset: 19, Line: 2, Column: 1}) {
+		t.Errorf("unexpected position %+v", tok.Pos())
+	}
+
+	// only the first line may be a shebang
+	l = New("let\n#!")
+	l.NextToken()
+	if tok := l.NextToken(); !tok.Is(token.ILLEGAL) {
+		t.Errorf("expected illegal, got %s", tok.Type())
+	}
+}
+
+func TestNextToken_Comments(t *testing.T) {
+	input := `// leading comment
+let x = 5; // trailing comment
+/* block
+   comment */ x / /* nested /* block */ comment */ 2;
+/**/`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.COMMENT, "// leading comment"},
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "// trailing comment"},
+		{token.COMMENT, "/* block\n   comment */"},
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.COMMENT, "/* nested /* block */ comment */"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "/**/"},
+		{token.EOF, ""},
+	}
+
+	t.Run("emitted", func(t *testing.T) {
+		l := New(input, WithComments())
+		for i, tt := range tests {
+			tok := l.NextToken()
+			if tok.Type() != tt.expectedType {
+				t.Fatalf("cases[%d]: expected token type %s, got %s", i, tt.expectedType, tok.Type())
+			}
+			if tok.Literal() != tt.expectedLiteral {
+				t.Fatalf("cases[%d]: expected literal '%s', got '%s'", i, tt.expectedLiteral, tok.Literal())
+			}
+		}
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		l := New(input)
+		for i, tt := range tests {
+			if tt.expectedType == token.COMMENT {
+				continue
+			}
+			tok := l.NextToken()
+			if tok.Type() != tt.expectedType {
+				t.Fatalf("cases[%d]: expected token type %s, got %s", i, tt.expectedType, tok.Type())
+			}
+		}
+	})
+}
+
+func TestNextToken_UnterminatedComment(t *testing.T) {
+	l := New("x /* outer /* inner */ never closed\n")
+
+	if tok := l.NextToken(); !tok.Is(token.IDENT) {
+		t.Fatalf("expected ident, got %s", tok.Type())
+	}
+	if tok := l.NextToken(); !tok.Is(token.EOF) {
+		t.Fatalf("expected eof, got %s", tok.Type())
+	}
+
+	diags := l.Diagnostics()
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+	}
+	if diags[0].Code != diag.UnterminatedComment || diags[0].Span.Start.Column != 3 {
+		t.Errorf("unexpected diagnostic: %s", diags[0].Error())
+	}
+}
+
+func TestNextToken_Numbers(t *testing.T) {
+	cases := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"0", token.INT, "0"},
+		{"007", token.INT, "007"},
+		{"1_000_000", token.INT, "1_000_000"},
+		{"0xFF", token.INT, "0xFF"},
+		{"0Xdead_beef", token.INT, "0Xdead_beef"},
+		{"0o17", token.INT, "0o17"},
+		{"0b1010", token.INT, "0b1010"},
+		{"0x1e-5", token.INT, "0x1e"},
+		{"1.5", token.FLOAT, "1.5"},
+		{"0.000_001", token.FLOAT, "0.000_001"},
+		{"1e9", token.FLOAT, "1e9"},
+		{"1e-9", token.FLOAT, "1e-9"},
+		{"2.5E+3", token.FLOAT, "2.5E+3"},
+		{"1e2-3", token.FLOAT, "1e2"},
+		{"3)", token.INT, "3"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			l := New(tc.input)
+			tok := l.NextToken()
+			if tok.Type() != tc.expectedType || tok.Literal() != tc.expectedLiteral {
+				t.Errorf("expected %s %q, got %s %q", tc.expectedType, tc.expectedLiteral, tok.Type(), tok.Literal())
+			}
+			if diags := l.Diagnostics(); len(diags) > 0 {
+				t.Errorf("unexpected diagnostic: %s", diags[0].Error())
+			}
+		})
+	}
+}
+
+func TestNextToken_MalformedNumbers(t *testing.T) {
+	cases := []struct {
+		input           string
+		expectedLiteral string
+		expectedHint    string
+	}{
+		{"2a", "2a", ""},
+		{".5", ".5", "put a 0 before the decimal point"},
+		{"1.", "1.", "put a digit after the decimal point"},
+		{"1.foo", "1.foo", ""},
+		{"1.2.3", "1.2.3", ""},
+		{"1e", "1e", "an exponent needs at least one digit"},
+		{"1e+", "1e+", "an exponent needs at least one digit"},
+		{"0x", "0x", "'0x' must be followed by hexadecimal digits"},
+		{"0b102", "0b102", "'2' is not a valid binary digit"},
+		{"0o8", "0o8", "'8' is not a valid octal digit"},
+		{"0x1.5", "0x1.5", "'.' is not a valid hexadecimal digit"},
+		{"1__000", "1__000", "'_' can only be used between digits"},
+		{"1_", "1_", "'_' can only be used between digits"},
+		{"0x_1", "0x_1", "'_' can only be used between digits"},
+		{"1._5", "1._5", "'_' can only be used between digits"},
+		{"1e_5", "1e_5", "'_' can only be used between digits"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			l := New(tc.input + " x")
+			tok := l.NextToken()
+			if !tok.Is(token.ILLEGAL) || tok.Literal() != tc.expectedLiteral {
+				t.Errorf("expected illegal %q, got %s %q", tc.expectedLiteral, tok.Type(), tok.Literal())
+			}
+
+			diags := l.Diagnostics()
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+			}
+			d := diags[0]
+			if d.Code != diag.MalformedNumber || d.Span.Start.Column != 1 || d.Span.End.Column != len(tc.input)+1 {
+				t.Errorf("unexpected diagnostic: %s", d.Error())
+			}
+			if d.Hint != tc.expectedHint {
+				t.Errorf("expected hint %q, got %q", tc.expectedHint, d.Hint)
+			}
+
+			if next := l.NextToken(); !next.Is(token.IDENT) {
+				t.Errorf("expected next token to be ident, got %s", next.Type())
+			}
+		})
+	}
+}
+
+func TestParseNumbers(t *testing.T) {
+	ints := map[string]int64{
+		"0":                   0,
+		"007":                 7,
+		"1_000":               1000,
+		"0xff":                255,
+		"0o17":                15,
+		"0B1010":              10,
+		"9223372036854775807": math.MaxInt64,
+	}
+	for lit, expected := range ints {
+		if v, err := ParseInt(lit); err != nil || v != expected {
+			t.Errorf("ParseInt(%q): expected %d, got %d, %v", lit, expected, v, err)
+		}
+	}
+	if _, err := ParseInt("9223372036854775808"); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("expected ErrRange, got %v", err)
+	}
+
+	floats := map[string]float64{
+		"1.5":     1.5,
+		"1_0.2_5": 10.25,
+		"1e3":     1000,
+		"2.5E-1":  0.25,
+	}
+	for lit, expected := range floats {
+		if v, err := ParseFloat(lit); err != nil || v != expected {
+			t.Errorf("ParseFloat(%q): expected %g, got %g, %v", lit, expected, v, err)
+		}
+	}
+	if _, err := ParseFloat("1e400"); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("expected ErrRange, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package lexer
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// Number literals are written in one of these forms, with '_' allowed
+// between any two digits:
+//
+//	123        decimal integer (leading zeros do not make it octal)
+//	0x7f 0o17 0b1010
+//	           hexadecimal, octal and binary integers (the prefix can be
+//	           upper case)
+//	1.5 1e-9 2.5E+3
+//	           floats, which need digits on both sides of the decimal point
+//
+// The lexer checks the form, but not whether the value fits in an int64 or
+// float64; that is left to ParseInt, ParseBigInt and ParseFloat.
+
+// readNumber assumes the current char is a digit, or a '.' followed by a
+// digit, and leaves the current char just past the end of the number. It
+// reads everything that could be part of a number, so that mistakes like
+// "1.2.3" or "0b102" are reported as one malformed number.
+func (l *Lexer) readNumber() token.Token {
+	position := l.position
+	prefixed := l.ch == '0' && strings.ContainsRune("xXoObB", l.peekChar())
+	for {
+		prev := l.ch
+		l.readChar()
+		exponentSign := (l.ch == '+' || l.ch == '-') && (prev == 'e' || prev == 'E') && !prefixed
+		if !isValidBodyOfIdent(l.ch) && l.ch != '.' && !exponentSign {
+			break
+		}
+	}
+
+	lit := l.input[position:l.position]
+	typ, hint := classifyNumber(lit)
+	if typ == token.ILLEGAL {
+		l.fail(diag.MalformedNumber, "malformed number '%s'", lit)
+		l.pending.Hint = hint
+	}
+	return token.New(typ, lit)
+}
+
+// classifyNumber returns whether lit is an INT or a FLOAT, or ILLEGAL with a
+// hint on what is wrong with it.
+func classifyNumber(lit string) (token.TokenType, string) {
+	if base, digits, ok := cutBasePrefix(lit); ok {
+		if digits == "" {
+			return token.ILLEGAL, fmt.Sprintf("'%s' must be followed by %s digits", lit[:2], baseNames[base])
+		}
+		for _, ch := range digits {
+			if ch != '_' && !isDigitIn(ch, base) {
+				return token.ILLEGAL, fmt.Sprintf("'%c' is not a valid %s digit", ch, baseNames[base])
+			}
+		}
+		if !separatesDigits(digits) {
+			return token.ILLEGAL, "'_' can only be used between digits"
+		}
+		return token.INT, ""
+	}
+
+	typ := token.INT
+	mantissa, exponent, hasExponent := strings.Cut(strings.NewReplacer("E", "e").Replace(lit), "e")
+	whole, fraction, hasPoint := strings.Cut(mantissa, ".")
+	parts := []string{whole}
+	if hasPoint {
+		typ = token.FLOAT
+		switch {
+		case whole == "":
+			return token.ILLEGAL, "put a 0 before the decimal point"
+		case fraction == "":
+			return token.ILLEGAL, "put a digit after the decimal point"
+		}
+		parts = append(parts, fraction)
+	}
+	if hasExponent {
+		typ = token.FLOAT
+		if strings.HasPrefix(exponent, "+") || strings.HasPrefix(exponent, "-") {
+			exponent = exponent[1:]
+		}
+		if exponent == "" {
+			return token.ILLEGAL, "an exponent needs at least one digit"
+		}
+		parts = append(parts, exponent)
+	}
+	for _, part := range parts {
+		for _, ch := range part {
+			if ch != '_' && !isDigit(ch) {
+				return token.ILLEGAL, ""
+			}
+		}
+		if !separatesDigits(part) {
+			return token.ILLEGAL, "'_' can only be used between digits"
+		}
+	}
+	return typ, ""
+}
+
+var baseNames = map[int]string{2: "binary", 8: "octal", 16: "hexadecimal"}
+
+// cutBasePrefix returns the base of lit, and its digits after the prefix, if
+// it starts with 0x, 0o or 0b.
+func cutBasePrefix(lit string) (int, string, bool) {
+	if len(lit) < 2 || lit[0] != '0' {
+		return 0, "", false
+	}
+	switch lit[1] {
+	case 'x', 'X':
+		return 16, lit[2:], true
+	case 'o', 'O':
+		return 8, lit[2:], true
+	case 'b', 'B':
+		return 2, lit[2:], true
+	}
+	return 0, "", false
+}
+
+func isDigitIn(ch rune, base int) bool {
+	switch {
+	case '0' <= ch && ch <= '9':
+		return int(ch-'0') < base
+	case 'a' <= ch && ch <= 'f', 'A' <= ch && ch <= 'F':
+		return base == 16
+	}
+	return false
+}
+
+// separatesDigits reports if each '_' in digits is between two digits.
+func separatesDigits(digits string) bool {
+	return !strings.HasPrefix(digits, "_") && !strings.HasSuffix(digits, "_") &&
+		!strings.Contains(digits, "__")
+}
+
+// ParseInt returns the value of an INT token's literal. If the value does
+// not fit in an int64, the error wraps strconv.ErrRange.
+func ParseInt(lit string) (int64, error) {
+	base, digits, ok := cutBasePrefix(lit)
+	if !ok {
+		base, digits = 10, lit
+	}
+	v, err := strconv.ParseInt(strings.ReplaceAll(digits, "_", ""), base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer literal '%s': %w", lit, err.(*strconv.NumError).Err)
+	}
+	return v, nil
+}
+
+// ParseBigInt is like ParseInt, but for values of any size.
+func ParseBigInt(lit string) (*big.Int, error) {
+	base, digits, ok := cutBasePrefix(lit)
+	if !ok {
+		base, digits = 10, lit
+	}
+	v, ok := new(big.Int).SetString(strings.ReplaceAll(digits, "_", ""), base)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer literal '%s'", lit)
+	}
+	return v, nil
+}
+
+// ParseFloat returns the value of a FLOAT token's literal. If the value is
+// too large for a float64, the error wraps strconv.ErrRange.
+func ParseFloat(lit string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(lit, "_", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid float literal '%s': %w", lit, err.(*strconv.NumError).Err)
+	}
+	return v, nil
+}
@@ -0,0 +1,70 @@
+package lexer
+
+import (
+	"unicode/utf8"
+
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/strlit"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// readString assumes the current char is the opening double quote. If the
+// string is terminated, the current char is left on the closing quote.
+// Otherwise the current char is left on the newline or EOF that ended it.
+// The returned literal includes the quotes.
+func (l *Lexer) readString() (string, bool) {
+	position := l.position
+	for {
+		l.readChar()
+		switch l.ch {
+		case '"':
+			return l.input[position : l.position+1], true
+		case 0, '\n':
+			l.fail(diag.UnterminatedString, "unterminated string")
+			return l.input[position:l.position], false
+		case utf8.RuneError:
+			if l.isInvalidChar() {
+				l.failInvalidChar()
+			}
+		case '\\':
+			start := l.pos()
+			_, n, err := strlit.DecodeEscape(l.input[l.position:])
+			if err != nil {
+				l.failSpan(diag.InvalidEscape, l.spanFrom(start, n), "%s", err)
+			}
+			// leave the current char on the last char of the escape sequence
+			for end := start.Offset + n; l.readPosition < end; {
+				l.readChar()
+			}
+		}
+	}
+}
+
+// readRawString assumes the current char is the opening backtick. Raw
+// strings have no escape sequences, and may span multiple lines.
+func (l *Lexer) readRawString() (string, bool) {
+	position := l.position
+	for {
+		l.readChar()
+		switch l.ch {
+		case '`':
+			return l.input[position : l.position+1], true
+		case 0:
+			l.fail(diag.UnterminatedString, "unterminated raw string")
+			return l.input[position:l.position], false
+		case utf8.RuneError:
+			if l.isInvalidChar() {
+				l.failInvalidChar()
+			}
+		}
+	}
+}
+
+// spanFrom returns the span that starts at start, and covers n bytes on the
+// same line.
+func (l *Lexer) spanFrom(start token.Pos, n int) token.Span {
+	end := start
+	end.Offset += n
+	end.Column += utf8.RuneCountInString(l.input[start.Offset:end.Offset])
+	return token.Span{File: l.filename, Start: start, End: end}
+}
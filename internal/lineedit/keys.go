@@ -0,0 +1,106 @@
+package lineedit
+
+import "unicode/utf8"
+
+// control characters
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyNewline   = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// special keys, which are sent as escape sequences, and are given values
+// outside the range of runes
+const (
+	keyUnknown = utf8.MaxRune + 1 + iota
+	keyUp
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+)
+
+type key struct {
+	r rune
+}
+
+func (k key) isPrintable() bool {
+	return k.r >= ' ' && k.r != keyBackspace && k.r <= utf8.MaxRune
+}
+
+// readKey reads a single key press, decoding escape sequences for the arrow
+// keys and friends.
+func (e *Editor) readKey() (key, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return key{}, err
+	}
+	if r != keyEscape {
+		return key{r: r}, nil
+	}
+
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return key{}, err
+	}
+	if r != '[' && r != 'O' {
+		return key{r: keyUnknown}, nil
+	}
+
+	// read any numeric parameters, up to the final byte of the sequence
+	var param []rune
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return key{}, err
+		}
+		if (r < '0' || r > '9') && r != ';' {
+			break
+		}
+		param = append(param, r)
+	}
+
+	switch r {
+	case 'A':
+		return key{r: keyUp}, nil
+	case 'B':
+		return key{r: keyDown}, nil
+	case 'C':
+		return key{r: keyRight}, nil
+	case 'D':
+		return key{r: keyLeft}, nil
+	case 'H':
+		return key{r: keyHome}, nil
+	case 'F':
+		return key{r: keyEnd}, nil
+	case '~':
+		switch string(param) {
+		case "1", "7":
+			return key{r: keyHome}, nil
+		case "4", "8":
+			return key{r: keyEnd}, nil
+		case "3":
+			return key{r: keyDelete}, nil
+		}
+	}
+	return key{r: keyUnknown}, nil
+}
@@ -0,0 +1,156 @@
+// Package lineedit reads lines of input from a terminal, with cursor
+// movement, history, reverse search and tab completion.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl-C.
+var ErrInterrupted = errors.New("interrupted")
+
+// DefaultMaxHistory is the number of lines of history kept by a new Editor.
+const DefaultMaxHistory = 1000
+
+// CompleteFunc is called when the user presses tab. It is given the text
+// before the cursor, and returns the number of runes at the end of that text
+// to replace, and the candidates to replace them with.
+type CompleteFunc func(before string) (wordLen int, candidates []string)
+
+// Editor reads lines of input. If the input is a terminal, it is put in raw
+// mode while a line is being read.
+type Editor struct {
+	in  *bufio.Reader
+	out io.Writer
+	fd  int // file descriptor of a terminal to put in raw mode, or -1
+
+	// Complete, if set, is used to complete the word before the cursor.
+	Complete CompleteFunc
+
+	// Highlight, if set, is used to add color to the line as it is shown. It
+	// must not change the visible width of the line.
+	Highlight func(line string) string
+
+	// MaxHistory is the number of lines of history to keep.
+	MaxHistory int
+
+	history     []string
+	historyPath string
+}
+
+func New(in io.Reader, out io.Writer) *Editor {
+	e := &Editor{
+		in:         bufio.NewReader(in),
+		out:        out,
+		fd:         -1,
+		MaxHistory: DefaultMaxHistory,
+	}
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		e.fd = int(f.Fd())
+	}
+	return e
+}
+
+// ReadLine shows prompt and reads a line of input, without the line ending.
+// It returns io.EOF if the user presses Ctrl-D on an empty line, and
+// ErrInterrupted if they press Ctrl-C.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if e.fd >= 0 {
+		state, err := term.MakeRaw(e.fd)
+		if err != nil {
+			return "", err
+		}
+		defer term.Restore(e.fd, state)
+	}
+
+	s := &lineState{e: e, prompt: prompt, histIndex: len(e.history)}
+	s.refresh()
+	for {
+		k, err := e.readKey()
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(s.buf) > 0 {
+				// treat the end of the input as the end of the line
+				k = key{r: keyEnter}
+			} else {
+				return "", err
+			}
+		}
+
+		line, done, err := s.handle(k)
+		if done || err != nil {
+			return line, err
+		}
+	}
+}
+
+// History returns the lines of history, oldest first.
+func (e *Editor) History() []string {
+	return e.history
+}
+
+// AddHistory adds line to the end of the history, unless it is blank or the
+// same as the previous line. If a history file was loaded, the line is also
+// appended to that file.
+func (e *Editor) AddHistory(line string) error {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return nil
+	}
+	e.history = append(e.history, line)
+	if over := len(e.history) - e.MaxHistory; over > 0 {
+		e.history = e.history[over:]
+	}
+
+	if e.historyPath == "" {
+		return nil
+	}
+	f, err := os.OpenFile(e.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(f, line)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// LoadHistory reads the history from the file at path, and remembers path so
+// that later calls to AddHistory append to it. A missing file is not an
+// error. If the file has more than MaxHistory lines, it is trimmed.
+func (e *Editor) LoadHistory(path string) error {
+	e.historyPath = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		lines = nil
+	}
+	trimmed := false
+	if over := len(lines) - e.MaxHistory; over > 0 {
+		lines = lines[over:]
+		trimmed = true
+	}
+	e.history = append(lines, e.history...)
+
+	if !trimmed {
+		return nil
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+}
@@ -0,0 +1,216 @@
+package lineedit
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	up    = "\x1b[A"
+	down  = "\x1b[B"
+	right = "\x1b[C"
+	left  = "\x1b[D"
+	home  = "\x1b[H"
+	end   = "\x1b[F"
+	del   = "\x1b[3~"
+)
+
+func TestReadLine(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain", "let x = 1;\r", "let x = 1;"},
+		{"newline", "abc\n", "abc"},
+		{"unicode", "héllo\r", "héllo"},
+		{"eof ends line", "abc", "abc"},
+		{"backspace", "abcd\x7f\x7fe\r", "abe"},
+		{"left and insert", "ac" + left + "b\r", "abc"},
+		{"home and end", "bc" + home + "a" + end + "d\r", "abcd"},
+		{"ctrl-a and ctrl-e", "bc\x01a\x05d\r", "abcd"},
+		{"ctrl-b and ctrl-f", "ac\x02\x02\x06b\r", "abc"},
+		{"delete", "abc" + home + del + "\r", "bc"},
+		{"ctrl-d deletes", "abc\x01\x04\r", "bc"},
+		{"ctrl-k", "abcd" + left + left + "\x0b\r", "ab"},
+		{"ctrl-u", "abcd" + left + "\x15\r", "d"},
+		{"ctrl-w", "let x = foo\x17bar\r", "let x = bar"},
+		{"right at end", "ab" + right + "c\r", "abc"},
+		{"unknown escape", "a\x1b[Zb\r", "ab"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New(strings.NewReader(tc.input), io.Discard)
+			line, err := e.ReadLine(">> ")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if line != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, line)
+			}
+		})
+	}
+}
+
+func TestReadLineErrors(t *testing.T) {
+	e := New(strings.NewReader("\x04"), io.Discard)
+	if _, err := e.ReadLine(">> "); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF for ctrl-d, got %v", err)
+	}
+
+	e = New(strings.NewReader("abc\x03"), io.Discard)
+	if _, err := e.ReadLine(">> "); !errors.Is(err, ErrInterrupted) {
+		t.Errorf("expected ErrInterrupted for ctrl-c, got %v", err)
+	}
+
+	e = New(strings.NewReader(""), io.Discard)
+	if _, err := e.ReadLine(">> "); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF at the end of the input, got %v", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"up", up + "\r", "let b = 2;"},
+		{"up twice", up + up + "\r", "let a = 1;"},
+		{"up past start", up + up + up + up + "\r", "let a = 1;"},
+		{"ctrl-p and ctrl-n", "\x10\x10\x0e\r", "let b = 2;"},
+		{"back to new line", "new" + up + down + "\r", "new"},
+		{"edit history", up + "\x7f\x7f3;\r", "let b = 3;"},
+		{"search", "\x12a =\r", "let a = 1;"},
+		{"search again", "\x12let\x12\r", "let a = 1;"},
+		{"search then edit", "\x12b" + end + "!\r", "let b = 2;!"},
+		{"search backspace", "\x12a\x7fb\r", "let b = 2;"},
+		{"search cancel", "x\x12a\x07\r", "x"},
+		{"search not found", "\x12zzz\r", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New(strings.NewReader(tc.input), io.Discard)
+			e.AddHistory("let a = 1;")
+			e.AddHistory("let b = 2;")
+
+			line, err := e.ReadLine(">> ")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if line != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, line)
+			}
+		})
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New(strings.NewReader(""), io.Discard)
+	e.MaxHistory = 3
+	if err := e.LoadHistory(path); err != nil {
+		t.Fatal(err)
+	}
+	e.AddHistory("four")
+	e.AddHistory("four") // duplicates are skipped
+	e.AddHistory("  ")   // as are blank lines
+
+	if h := strings.Join(e.History(), ","); h != "two,three,four" {
+		t.Errorf("unexpected history %s", h)
+	}
+
+	// a new editor sees the saved history, trimmed to its maximum
+	e = New(strings.NewReader(""), io.Discard)
+	e.MaxHistory = 2
+	if err := e.LoadHistory(path); err != nil {
+		t.Fatal(err)
+	}
+	if h := strings.Join(e.History(), ","); h != "three,four" {
+		t.Errorf("unexpected history %s", h)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "three\nfour\n" {
+		t.Errorf("expected the history file to be trimmed, got %q", data)
+	}
+
+	// a missing file is fine
+	e = New(strings.NewReader(""), io.Discard)
+	if err := e.LoadHistory(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	words := []string{"let", "len", "length", "return"}
+	complete := func(before string) (int, []string) {
+		i := strings.LastIndexAny(before, " (") + 1
+		var matches []string
+		for _, w := range words {
+			if strings.HasPrefix(w, before[i:]) {
+				matches = append(matches, w)
+			}
+		}
+		return len(before) - i, matches
+	}
+
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+		output   string
+	}{
+		{"unique", "ret\t\r", "return", ""},
+		{"common prefix", "le\tn\r", "len", ""},
+		{"mid line", "x = (re)" + left + "\t\r", "x = (return)", ""},
+		{"choices", "len\t\r", "len", "len  length"},
+		{"no match", "zz\t\r", "zz", "\a"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out strings.Builder
+			e := New(strings.NewReader(tc.input), &out)
+			e.Complete = complete
+
+			line, err := e.ReadLine(">> ")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if line != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, line)
+			}
+			if !strings.Contains(out.String(), tc.output) {
+				t.Errorf("expected output to contain %q, got %q", tc.output, out.String())
+			}
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	var out strings.Builder
+	e := New(strings.NewReader("ab"+left+"\r"), &out)
+	e.Highlight = func(line string) string { return "<" + line + ">" }
+
+	line, err := e.ReadLine(">> ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "ab" {
+		t.Errorf("expected the line without highlighting, got %q", line)
+	}
+	// the cursor is positioned by the visible width of the line
+	if !strings.Contains(out.String(), "\r>> <ab>\x1b[K\x1b[1D") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
@@ -0,0 +1,249 @@
+package lineedit
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// lineState is the state of the line being edited.
+type lineState struct {
+	e      *Editor
+	prompt string
+	buf    []rune
+	pos    int // cursor position in buf
+
+	histIndex int    // index in history of the line shown, or len(history) for a new line
+	saved     []rune // the new line, while showing a line from history
+
+	search *searchState // non-nil during a reverse search
+}
+
+type searchState struct {
+	query  []rune
+	index  int  // index in history of the current match
+	failed bool // no line in history matches query
+}
+
+// handle acts on a key press. When the line is finished, it returns the line
+// and done.
+func (s *lineState) handle(k key) (line string, done bool, err error) {
+	if s.search != nil {
+		if s.handleSearch(k) {
+			return "", false, nil
+		}
+	}
+
+	switch k.r {
+	case keyEnter, keyNewline:
+		s.write("\r\n")
+		return string(s.buf), true, nil
+	case keyCtrlC:
+		s.write("^C\r\n")
+		return "", true, ErrInterrupted
+	case keyCtrlD:
+		if len(s.buf) == 0 {
+			s.write("\r\n")
+			return "", true, io.EOF
+		}
+		s.deleteAt(s.pos)
+	case keyBackspace, keyCtrlH:
+		if s.pos > 0 {
+			s.pos--
+			s.deleteAt(s.pos)
+		}
+	case keyDelete:
+		s.deleteAt(s.pos)
+	case keyCtrlA, keyHome:
+		s.pos = 0
+	case keyCtrlE, keyEnd:
+		s.pos = len(s.buf)
+	case keyCtrlB, keyLeft:
+		if s.pos > 0 {
+			s.pos--
+		}
+	case keyCtrlF, keyRight:
+		if s.pos < len(s.buf) {
+			s.pos++
+		}
+	case keyCtrlK:
+		s.buf = s.buf[:s.pos]
+	case keyCtrlU:
+		s.buf = append([]rune(nil), s.buf[s.pos:]...)
+		s.pos = 0
+	case keyCtrlW:
+		start := s.pos
+		for start > 0 && unicode.IsSpace(s.buf[start-1]) {
+			start--
+		}
+		for start > 0 && !unicode.IsSpace(s.buf[start-1]) {
+			start--
+		}
+		s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+		s.pos = start
+	case keyCtrlL:
+		s.write("\x1b[H\x1b[2J")
+	case keyCtrlP, keyUp:
+		s.showHistory(s.histIndex - 1)
+	case keyCtrlN, keyDown:
+		s.showHistory(s.histIndex + 1)
+	case keyCtrlR:
+		s.search = &searchState{index: len(s.e.history)}
+	case keyTab:
+		s.complete()
+	default:
+		if k.isPrintable() {
+			s.insert([]rune{k.r})
+		}
+	}
+
+	s.refresh()
+	return "", false, nil
+}
+
+// handleSearch acts on a key press during a reverse search. It returns false
+// if the key ended the search, and should be handled as a normal key press.
+func (s *lineState) handleSearch(k key) bool {
+	switch {
+	case k.r == keyCtrlR:
+		s.find(s.search.index - 1)
+	case k.r == keyBackspace || k.r == keyCtrlH:
+		if len(s.search.query) > 0 {
+			s.search.query = s.search.query[:len(s.search.query)-1]
+			s.find(len(s.e.history) - 1)
+		}
+	case k.r == keyCtrlG || k.r == keyCtrlC:
+		// cancel, leaving the line as it was
+		s.search = nil
+	case k.isPrintable():
+		s.search.query = append(s.search.query, k.r)
+		s.find(s.search.index)
+	default:
+		// accept the match
+		if s.search.index < len(s.e.history) {
+			s.buf = []rune(s.e.history[s.search.index])
+			s.pos = len(s.buf)
+			s.histIndex = len(s.e.history)
+		}
+		s.search = nil
+		return false
+	}
+	s.refresh()
+	return true
+}
+
+// find looks back through history for query, starting at index from.
+func (s *lineState) find(from int) {
+	query := string(s.search.query)
+	for i := min(from, len(s.e.history)-1); i >= 0; i-- {
+		if strings.Contains(s.e.history[i], query) {
+			s.search.index = i
+			s.search.failed = false
+			return
+		}
+	}
+	s.search.failed = true
+}
+
+// showHistory replaces the line with the line at index i in history. Index
+// len(history) is the new line being written.
+func (s *lineState) showHistory(i int) {
+	if i < 0 || i > len(s.e.history) || i == s.histIndex {
+		return
+	}
+	if s.histIndex == len(s.e.history) {
+		s.saved = append([]rune(nil), s.buf...)
+	}
+	s.histIndex = i
+	if i == len(s.e.history) {
+		s.buf = s.saved
+	} else {
+		s.buf = []rune(s.e.history[i])
+	}
+	s.pos = len(s.buf)
+}
+
+func (s *lineState) complete() {
+	if s.e.Complete == nil {
+		return
+	}
+	wordLen, candidates := s.e.Complete(string(s.buf[:s.pos]))
+	if len(candidates) == 0 || wordLen > s.pos {
+		s.write("\a")
+		return
+	}
+
+	start := s.pos - wordLen
+	replacement := commonPrefix(candidates)
+	if len(candidates) > 1 && len([]rune(replacement)) <= wordLen {
+		// no progress can be made, so show the choices
+		s.write("\r\n" + strings.Join(candidates, "  ") + "\r\n")
+		return
+	}
+
+	rest := append([]rune(nil), s.buf[s.pos:]...)
+	s.buf = append(s.buf[:start], []rune(replacement)...)
+	s.pos = len(s.buf)
+	s.buf = append(s.buf, rest...)
+}
+
+func commonPrefix(words []string) string {
+	prefix := []rune(words[0])
+	for _, w := range words[1:] {
+		r := []rune(w)
+		n := 0
+		for n < len(prefix) && n < len(r) && prefix[n] == r[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return string(prefix)
+}
+
+func (s *lineState) insert(r []rune) {
+	s.buf = append(s.buf[:s.pos], append(r, s.buf[s.pos:]...)...)
+	s.pos += len(r)
+}
+
+func (s *lineState) deleteAt(i int) {
+	if i < len(s.buf) {
+		s.buf = append(s.buf[:i], s.buf[i+1:]...)
+	}
+}
+
+// refresh redraws the line, and puts the cursor in the right place.
+func (s *lineState) refresh() {
+	prompt, buf, pos := s.prompt, s.buf, s.pos
+	if s.search != nil {
+		failed := ""
+		if s.search.failed {
+			failed = "failed "
+		}
+		prompt = fmt.Sprintf("(%sreverse-i-search)`%s': ", failed, string(s.search.query))
+		buf = nil
+		if s.search.index < len(s.e.history) {
+			buf = []rune(s.e.history[s.search.index])
+		}
+		pos = len(buf)
+	}
+
+	text := string(buf)
+	if s.search == nil && s.e.Highlight != nil {
+		text = s.e.Highlight(text)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\r")
+	sb.WriteString(prompt)
+	sb.WriteString(text)
+	sb.WriteString("\x1b[K")
+	if n := len(buf) - pos; n > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dD", n)
+	}
+	s.write(sb.String())
+}
+
+func (s *lineState) write(str string) {
+	io.WriteString(s.e.out, str)
+}
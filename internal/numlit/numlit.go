@@ -0,0 +1,19 @@
+// Package numlit writes numbers as Hai number literals, for the packages that
+// print values and syntax trees, so that they need not depend on the lexer.
+package numlit
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FormatFloat returns v as Hai would write it, which for any finite v is a
+// FLOAT literal (or its negation) with the same value.
+func FormatFloat(v float64) string {
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		// make it clear that it is not an integer
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,24 @@
+package numlit
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatFloat(t *testing.T) {
+	cases := map[float64]string{
+		1:           "1.0",
+		-2:          "-2.0",
+		1.5:         "1.5",
+		100000:      "100000.0",
+		1e21:        "1e+21",
+		1e-7:        "1e-07",
+		math.Inf(1): "+Inf",
+		math.NaN():  "NaN",
+	}
+	for v, expected := range cases {
+		if actual := FormatFloat(v); actual != expected {
+			t.Errorf("FormatFloat(%g): expected %q, got %q", v, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package object
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// Builtins are the functions available to every Hai program, with puts
+// writing to os.Stdout. The compiler refers to them by their index, so new
+// builtins must be added to the end.
+var Builtins = NewBuiltins(os.Stdout)
+
+// NewBuiltins returns the builtins, in the same order as Builtins, with puts
+// writing to out.
+func NewBuiltins(out io.Writer) []*Builtin {
+	return []*Builtin{
+		{
+			Name: "len",
+			Fn: func(args ...Object) Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments: want=1, got=%d", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *String:
+					return &Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+				default:
+					return newError("argument to `len` not supported, got %s", args[0].Type())
+				}
+			},
+		},
+		{
+			Name: "puts",
+			Fn: func(args ...Object) Object {
+				for _, arg := range args {
+					fmt.Fprintln(out, arg.Inspect())
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// GetBuiltinByName returns the builtin in builtins with the given name, or
+// nil if there is no such builtin.
+func GetBuiltinByName(builtins []*Builtin, name string) *Builtin {
+	for _, b := range builtins {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+func newError(format string, a ...any) *Error {
+	return &Error{Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,128 @@
+package object
+
+import (
+	"math"
+	"math/big"
+)
+
+// NewBigInteger returns an Integer with the value of b, which it takes
+// ownership of.
+func NewBigInteger(b *big.Int) *Integer {
+	if b.IsInt64() {
+		return &Integer{Value: b.Int64()}
+	}
+	return &Integer{Big: b}
+}
+
+// IsBig reports if i is too large to fit in an int64.
+func (i *Integer) IsBig() bool {
+	return i.Big != nil
+}
+
+// BigInt returns the value of i as a new big.Int.
+func (i *Integer) BigInt() *big.Int {
+	if i.Big != nil {
+		return new(big.Int).Set(i.Big)
+	}
+	return big.NewInt(i.Value)
+}
+
+// Float returns the value of i as the nearest float64.
+func (i *Integer) Float() float64 {
+	if i.Big != nil {
+		f, _ := new(big.Float).SetInt(i.Big).Float64()
+		return f
+	}
+	return float64(i.Value)
+}
+
+// Sign returns -1, 0 or +1, depending on whether i is negative, zero or
+// positive.
+func (i *Integer) Sign() int {
+	switch {
+	case i.Big != nil:
+		return i.Big.Sign()
+	case i.Value < 0:
+		return -1
+	case i.Value > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Cmp compares i and j, and returns -1, 0 or +1, depending on whether i is
+// less than, equal to, or greater than j.
+func (i *Integer) Cmp(j *Integer) int {
+	if i.Big == nil && j.Big == nil {
+		switch {
+		case i.Value < j.Value:
+			return -1
+		case i.Value > j.Value:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return i.BigInt().Cmp(j.BigInt())
+}
+
+// The arithmetic below works on int64s when it can, and falls back to
+// big.Int when the result would overflow.
+
+// Add returns i + j.
+func (i *Integer) Add(j *Integer) *Integer {
+	if i.Big == nil && j.Big == nil {
+		sum := i.Value + j.Value
+		// overflow happened if the operands have the same sign, and the sum
+		// does not
+		if (i.Value^sum)&(j.Value^sum) >= 0 {
+			return &Integer{Value: sum}
+		}
+	}
+	return NewBigInteger(new(big.Int).Add(i.BigInt(), j.BigInt()))
+}
+
+// Sub returns i - j.
+func (i *Integer) Sub(j *Integer) *Integer {
+	if i.Big == nil && j.Big == nil {
+		diff := i.Value - j.Value
+		// overflow happened if the operands have different signs, and the
+		// difference does not have the sign of i
+		if (i.Value^j.Value)&(i.Value^diff) >= 0 {
+			return &Integer{Value: diff}
+		}
+	}
+	return NewBigInteger(new(big.Int).Sub(i.BigInt(), j.BigInt()))
+}
+
+// Mul returns i * j.
+func (i *Integer) Mul(j *Integer) *Integer {
+	if i.Big == nil && j.Big == nil {
+		a, b := i.Value, j.Value
+		if a == 0 || b == 0 {
+			return &Integer{Value: 0}
+		}
+		product := a * b
+		if product/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64) {
+			return &Integer{Value: product}
+		}
+	}
+	return NewBigInteger(new(big.Int).Mul(i.BigInt(), j.BigInt()))
+}
+
+// Quo returns i / j, truncated towards zero. j must not be zero.
+func (i *Integer) Quo(j *Integer) *Integer {
+	if i.Big == nil && j.Big == nil && !(i.Value == math.MinInt64 && j.Value == -1) {
+		return &Integer{Value: i.Value / j.Value}
+	}
+	return NewBigInteger(new(big.Int).Quo(i.BigInt(), j.BigInt()))
+}
+
+// Neg returns -i.
+func (i *Integer) Neg() *Integer {
+	if i.Big == nil && i.Value != math.MinInt64 {
+		return &Integer{Value: -i.Value}
+	}
+	return NewBigInteger(new(big.Int).Neg(i.BigInt()))
+}
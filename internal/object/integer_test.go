@@ -0,0 +1,90 @@
+package object
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestIntegerArithmetic(t *testing.T) {
+	const (
+		max = math.MaxInt64
+		min = math.MinInt64
+	)
+	cases := []struct {
+		left     int64
+		op       string
+		right    int64
+		expected string
+	}{
+		{1, "+", 2, "3"},
+		{max, "+", 1, "9223372036854775808"},
+		{min, "+", -1, "-9223372036854775809"},
+		{max, "+", min, "-1"},
+		{1, "-", 2, "-1"},
+		{min, "-", 1, "-9223372036854775809"},
+		{max, "-", -1, "9223372036854775808"},
+		{-1, "-", min, "9223372036854775807"},
+		{3, "*", -4, "-12"},
+		{max, "*", 2, "18446744073709551614"},
+		{min, "*", -1, "9223372036854775808"},
+		{-1, "*", min, "9223372036854775808"},
+		{0, "*", min, "0"},
+		{1 << 32, "*", 1 << 32, "18446744073709551616"},
+		{7, "/", -2, "-3"},
+		{min, "/", -1, "9223372036854775808"},
+	}
+
+	for _, tc := range cases {
+		left, right := &Integer{Value: tc.left}, &Integer{Value: tc.right}
+		var result *Integer
+		switch tc.op {
+		case "+":
+			result = left.Add(right)
+		case "-":
+			result = left.Sub(right)
+		case "*":
+			result = left.Mul(right)
+		case "/":
+			result = left.Quo(right)
+		}
+		if result.Inspect() != tc.expected {
+			t.Errorf("%d %s %d: expected %s, got %s", tc.left, tc.op, tc.right, tc.expected, result.Inspect())
+		}
+		if result.IsBig() == result.BigInt().IsInt64() {
+			t.Errorf("%d %s %d: Big is set wrongly for %s", tc.left, tc.op, tc.right, result.Inspect())
+		}
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	b, _ := new(big.Int).SetString("100000000000000000000", 10)
+	i := NewBigInteger(b)
+	if !i.IsBig() {
+		t.Fatal("expected a big integer")
+	}
+
+	// results that fit in an int64 go back to being small
+	if diff := i.Sub(NewBigInteger(new(big.Int).Sub(b, big.NewInt(5)))); diff.IsBig() || diff.Value != 5 {
+		t.Errorf("expected a small 5, got %#v", diff)
+	}
+	if q := i.Quo(i); q.IsBig() || q.Value != 1 {
+		t.Errorf("expected a small 1, got %#v", q)
+	}
+
+	if neg := i.Neg(); neg.Inspect() != "-100000000000000000000" || neg.Cmp(i) >= 0 {
+		t.Errorf("unexpected negation %s", neg.Inspect())
+	}
+	if (&Integer{Value: math.MinInt64}).Neg().Inspect() != "9223372036854775808" {
+		t.Error("unexpected negation of MinInt64")
+	}
+	if i.Cmp(&Integer{Value: math.MaxInt64}) != 1 || (&Integer{Value: 1}).Cmp(i) != -1 {
+		t.Error("unexpected comparison")
+	}
+	if i.Float() != 1e20 {
+		t.Errorf("expected 1e20, got %g", i.Float())
+	}
+	if i.Sign() != 1 || i.Neg().Sign() != -1 {
+		t.Error("unexpected sign")
+	}
+}
@@ -0,0 +1,153 @@
+package object
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/code"
+	"github.com/danbrakeley/hai/internal/numlit"
+)
+
+//go:generate enumer -type=ObjectType -json -transform=snake
+type ObjectType uint8
+
+const (
+	NULL ObjectType = iota
+	INTEGER
+	FLOAT
+	BOOLEAN
+	STRING
+	RETURN_VALUE
+	ERROR
+	FUNCTION
+	COMPILED_FUNCTION
+	CLOSURE
+	BUILTIN
+)
+
+type Object interface {
+	Type() ObjectType
+	Inspect() string
+}
+
+// Integer is an integer of any size. Values that fit in an int64 are held in
+// Value. Larger ones are held in Big instead, which is nil otherwise, and
+// should be treated as immutable. Integers that are built with NewBigInteger
+// or returned by arithmetic keep to this.
+type Integer struct {
+	Value int64
+	Big   *big.Int
+}
+
+func (i *Integer) Type() ObjectType { return INTEGER }
+func (i *Integer) Inspect() string {
+	if i.Big != nil {
+		return i.Big.String()
+	}
+	return strconv.FormatInt(i.Value, 10)
+}
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT }
+func (f *Float) Inspect() string  { return numlit.FormatFloat(f.Value) }
+
+// AsFloat returns the value of an Integer or a Float as a float64, and
+// whether obj is one of them.
+func AsFloat(obj Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return obj.Float(), true
+	case *Float:
+		return obj.Value, true
+	default:
+		return 0, false
+	}
+}
+
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOLEAN }
+func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
+
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING }
+func (s *String) Inspect() string  { return s.Value }
+
+type Null struct{}
+
+func (n *Null) Type() ObjectType { return NULL }
+func (n *Null) Inspect() string  { return "null" }
+
+// ReturnValue wraps the value of a return statement while it unwinds out of
+// any enclosing blocks.
+type ReturnValue struct {
+	Value Object
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+
+type Error struct {
+	Message string
+}
+
+func (e *Error) Type() ObjectType { return ERROR }
+func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
+
+type Function struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION }
+func (f *Function) Inspect() string {
+	params := make([]string, 0, len(f.Parameters))
+	for _, p := range f.Parameters {
+		params = append(params, p.Value)
+	}
+	return "fn(" + strings.Join(params, ", ") + ") { ... }"
+}
+
+// BuiltinFunction is a function implemented in Go. It returns nil to
+// indicate null, and an *Error to indicate a runtime error.
+type BuiltinFunction func(args ...Object) Object
+
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN }
+func (b *Builtin) Inspect() string  { return "builtin " + b.Name }
+
+// CompiledFunction is the bytecode produced by compiling a function literal.
+type CompiledFunction struct {
+	Instructions  code.Instructions
+	NumLocals     int
+	NumParameters int
+}
+
+func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FUNCTION }
+func (cf *CompiledFunction) Inspect() string  { return fmt.Sprintf("CompiledFunction[%p]", cf) }
+
+// Closure is a compiled function, along with the values of any variables it
+// captured from enclosing scopes when it was created.
+type Closure struct {
+	Fn   *CompiledFunction
+	Free []Object
+}
+
+func (c *Closure) Type() ObjectType { return CLOSURE }
+func (c *Closure) Inspect() string  { return fmt.Sprintf("Closure[%p]", c) }
@@ -0,0 +1,132 @@
+// Code generated by "enumer -type=ObjectType -json -transform=snake"; DO NOT EDIT.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const _ObjectTypeName = "nullintegerfloatbooleanstringreturn_valueerrorfunctioncompiled_functionclosurebuiltin"
+
+var _ObjectTypeIndex = [...]uint8{0, 4, 11, 16, 23, 29, 41, 46, 54, 71, 78, 85}
+
+const _ObjectTypeLowerName = "nullintegerfloatbooleanstringreturn_valueerrorfunctioncompiled_functionclosurebuiltin"
+
+func (i ObjectType) String() string {
+	if i >= ObjectType(len(_ObjectTypeIndex)-1) {
+		return fmt.Sprintf("ObjectType(%d)", i)
+	}
+	return _ObjectTypeName[_ObjectTypeIndex[i]:_ObjectTypeIndex[i+1]]
+}
+
+// An "invalid array index" compiler error signifies that the constant values have changed.
+// Re-run the stringer command to generate them again.
+func _ObjectTypeNoOp() {
+	var x [1]struct{}
+	_ = x[NULL-(0)]
+	_ = x[INTEGER-(1)]
+	_ = x[FLOAT-(2)]
+	_ = x[BOOLEAN-(3)]
+	_ = x[STRING-(4)]
+	_ = x[RETURN_VALUE-(5)]
+	_ = x[ERROR-(6)]
+	_ = x[FUNCTION-(7)]
+	_ = x[COMPILED_FUNCTION-(8)]
+	_ = x[CLOSURE-(9)]
+	_ = x[BUILTIN-(10)]
+}
+
+var _ObjectTypeValues = []ObjectType{NULL, INTEGER, FLOAT, BOOLEAN, STRING, RETURN_VALUE, ERROR, FUNCTION, COMPILED_FUNCTION, CLOSURE, BUILTIN}
+
+var _ObjectTypeNameToValueMap = map[string]ObjectType{
+	_ObjectTypeName[0:4]:        NULL,
+	_ObjectTypeLowerName[0:4]:   NULL,
+	_ObjectTypeName[4:11]:       INTEGER,
+	_ObjectTypeLowerName[4:11]:  INTEGER,
+	_ObjectTypeName[11:16]:      FLOAT,
+	_ObjectTypeLowerName[11:16]: FLOAT,
+	_ObjectTypeName[16:23]:      BOOLEAN,
+	_ObjectTypeLowerName[16:23]: BOOLEAN,
+	_ObjectTypeName[23:29]:      STRING,
+	_ObjectTypeLowerName[23:29]: STRING,
+	_ObjectTypeName[29:41]:      RETURN_VALUE,
+	_ObjectTypeLowerName[29:41]: RETURN_VALUE,
+	_ObjectTypeName[41:46]:      ERROR,
+	_ObjectTypeLowerName[41:46]: ERROR,
+	_ObjectTypeName[46:54]:      FUNCTION,
+	_ObjectTypeLowerName[46:54]: FUNCTION,
+	_ObjectTypeName[54:71]:      COMPILED_FUNCTION,
+	_ObjectTypeLowerName[54:71]: COMPILED_FUNCTION,
+	_ObjectTypeName[71:78]:      CLOSURE,
+	_ObjectTypeLowerName[71:78]: CLOSURE,
+	_ObjectTypeName[78:85]:      BUILTIN,
+	_ObjectTypeLowerName[78:85]: BUILTIN,
+}
+
+var _ObjectTypeNames = []string{
+	_ObjectTypeName[0:4],
+	_ObjectTypeName[4:11],
+	_ObjectTypeName[11:16],
+	_ObjectTypeName[16:23],
+	_ObjectTypeName[23:29],
+	_ObjectTypeName[29:41],
+	_ObjectTypeName[41:46],
+	_ObjectTypeName[46:54],
+	_ObjectTypeName[54:71],
+	_ObjectTypeName[71:78],
+	_ObjectTypeName[78:85],
+}
+
+// ObjectTypeString retrieves an enum value from the enum constants string name.
+// Throws an error if the param is not part of the enum.
+func ObjectTypeString(s string) (ObjectType, error) {
+	if val, ok := _ObjectTypeNameToValueMap[s]; ok {
+		return val, nil
+	}
+
+	if val, ok := _ObjectTypeNameToValueMap[strings.ToLower(s)]; ok {
+		return val, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to ObjectType values", s)
+}
+
+// ObjectTypeValues returns all values of the enum
+func ObjectTypeValues() []ObjectType {
+	return _ObjectTypeValues
+}
+
+// ObjectTypeStrings returns a slice of all String values of the enum
+func ObjectTypeStrings() []string {
+	strs := make([]string, len(_ObjectTypeNames))
+	copy(strs, _ObjectTypeNames)
+	return strs
+}
+
+// IsAObjectType returns "true" if the value is listed in the enum definition. "false" otherwise
+func (i ObjectType) IsAObjectType() bool {
+	for _, v := range _ObjectTypeValues {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON implements the json.Marshaler interface for ObjectType
+func (i ObjectType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for ObjectType
+func (i *ObjectType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("ObjectType should be a string, got %s", data)
+	}
+
+	var err error
+	*i, err = ObjectTypeString(s)
+	return err
+}
@@ -0,0 +1,391 @@
+package parser
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/strlit"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// operator precedence, from loosest to tightest binding
+const (
+	_ int = iota
+	LOWEST
+	EQUALS      // ==
+	LESSGREATER // > or <
+	SUM         // +
+	PRODUCT     // *
+	PREFIX      // -X or !X
+	CALL        // myFunction(X)
+)
+
+// precedences of infix operators
+var precedences = map[token.TokenType]int{
+	token.EQ:       EQUALS,
+	token.NOT_EQ:   EQUALS,
+	token.LT:       LESSGREATER,
+	token.GT:       LESSGREATER,
+	token.PLUS:     SUM,
+	token.MINUS:    SUM,
+	token.SLASH:    PRODUCT,
+	token.ASTERISK: PRODUCT,
+	token.LPAREN:   CALL,
+}
+
+// Precedence returns the precedence of t when it is used as an infix
+// operator, or LOWEST if it is not one.
+func Precedence(t token.TokenType) int {
+	if p, ok := precedences[t]; ok {
+		return p
+	}
+	return LOWEST
+}
+
+func (p *Parser) peekPrecedence() int {
+	if p, ok := precedences[p.peekToken.Type()]; ok {
+		return p
+	}
+	return LOWEST
+}
+
+func (p *Parser) curPrecedence() int {
+	if p, ok := precedences[p.curToken.Type()]; ok {
+		return p
+	}
+	return LOWEST
+}
+
+// parseExpression assumes curToken is the first token of the expression, and
+// leaves curToken on the last token of the expression
+func (p *Parser) parseExpression(precedence int) ast.Expression {
+	p.nesting++
+	defer func() { p.nesting-- }()
+	if !p.checkNesting(p.nesting) {
+		return nil
+	}
+
+	prefix := p.prefixParseFns[p.curToken.Type()]
+	if prefix == nil {
+		p.noPrefixParseFnError()
+		return nil
+	}
+	left := prefix()
+	if left == nil {
+		return nil
+	}
+
+	for depth := p.nesting + 1; !p.peekToken.Is(token.SEMICOLON) && precedence < p.peekPrecedence(); depth++ {
+		infix := p.infixParseFns[p.peekToken.Type()]
+		if infix == nil {
+			return left
+		}
+		// left becomes an operand of the infix expression, one level deeper
+		if !p.checkNesting(depth) {
+			return nil
+		}
+		p.nextToken()
+		left = infix(left)
+		if left == nil {
+			return nil
+		}
+	}
+
+	return left
+}
+
+// checkNesting reports if an expression at the given depth is within
+// MaxNesting, and reports an error if it is not.
+func (p *Parser) checkNesting(depth int) bool {
+	if depth <= MaxNesting {
+		return true
+	}
+	p.errorf(diag.NestedTooDeeply, p.curToken.Span(),
+		"expression is nested too deeply (the most is %d levels)", MaxNesting)
+	return false
+}
+
+func (p *Parser) noPrefixParseFnError() {
+	if p.curToken.Is(token.ILLEGAL) {
+		// the lexer already reported this token
+		return
+	}
+	d := diag.Errorf(diag.MissingExpression, p.curToken.Span(),
+		"expected an expression, got %s instead", p.curToken.Type())
+	d.Actual = p.curToken.Type()
+	p.report(d)
+}
+
+func (p *Parser) parseIdentifier() ast.Expression {
+	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal()}
+}
+
+func (p *Parser) parseIntegerLiteral() ast.Expression {
+	value, err := lexer.ParseInt(p.curToken.Literal())
+	if errors.Is(err, strconv.ErrRange) {
+		var b *big.Int
+		if b, err = lexer.ParseBigInt(p.curToken.Literal()); err == nil {
+			return &ast.IntegerLiteral{Token: p.curToken, Big: b}
+		}
+	}
+	if err != nil {
+		p.errorf(diag.InvalidInteger, p.curToken.Span(),
+			"could not parse '%s' as an integer", p.curToken.Literal())
+		return nil
+	}
+	return &ast.IntegerLiteral{Token: p.curToken, Value: value}
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	value, err := lexer.ParseFloat(p.curToken.Literal())
+	switch {
+	case errors.Is(err, strconv.ErrRange):
+		p.errorf(diag.InvalidFloat, p.curToken.Span(),
+			"float literal '%s' is too large", p.curToken.Literal())
+		return nil
+	case err != nil:
+		p.errorf(diag.InvalidFloat, p.curToken.Span(),
+			"could not parse '%s' as a float", p.curToken.Literal())
+		return nil
+	}
+	return &ast.FloatLiteral{Token: p.curToken, Value: value}
+}
+
+func (p *Parser) parseStringLiteral() ast.Expression {
+	value, err := strlit.Unquote(p.curToken.Literal())
+	if err != nil {
+		p.errorf(diag.InvalidString, p.curToken.Span(), "invalid string literal: %s", err)
+		return nil
+	}
+	return &ast.StringLiteral{Token: p.curToken, Value: value}
+}
+
+func (p *Parser) parseBoolean() ast.Expression {
+	return &ast.Boolean{Token: p.curToken, Value: p.curToken.Is(token.TRUE)}
+}
+
+// parsePrefixExpression assumes curToken is the prefix operator
+func (p *Parser) parsePrefixExpression() ast.Expression {
+	expr := &ast.PrefixExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Literal(),
+	}
+	p.nextToken()
+
+	expr.Right = p.parseExpression(PREFIX)
+	if expr.Right == nil {
+		return nil
+	}
+	return expr
+}
+
+// parseInfixExpression assumes curToken is the infix operator
+func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
+	expr := &ast.InfixExpression{
+		Token:    p.curToken,
+		Left:     left,
+		Operator: p.curToken.Literal(),
+	}
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+
+	expr.Right = p.parseExpression(precedence)
+	if expr.Right == nil {
+		return nil
+	}
+	return expr
+}
+
+// parseGroupedExpression assumes curToken is LPAREN
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	p.nextToken()
+
+	expr := p.parseExpression(LOWEST)
+	if expr == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	return expr
+}
+
+// parseIfExpression assumes curToken is IF
+func (p *Parser) parseIfExpression() ast.Expression {
+	expr := &ast.IfExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	p.nextToken()
+
+	expr.Condition = p.parseExpression(LOWEST)
+	if expr.Condition == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+
+	expr.Consequence = p.parseBlockStatement()
+	if expr.Consequence == nil {
+		return nil
+	}
+
+	if p.peekToken.Is(token.ELSE) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		p.nextToken()
+
+		expr.Alternative = p.parseBlockStatement()
+		if expr.Alternative == nil {
+			return nil
+		}
+	}
+
+	return expr
+}
+
+// parseFunctionLiteral assumes curToken is FUNCTION
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	lit := &ast.FunctionLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	lit.Parameters = p.parseFunctionParameters()
+	if lit.Parameters == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+
+	lit.Body = p.parseBlockStatement()
+	if lit.Body == nil {
+		return nil
+	}
+
+	return lit
+}
+
+// parseFunctionParameters assumes curToken is LPAREN, and leaves curToken on
+// the matching RPAREN. Returns nil if the parameter list is malformed.
+func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+	idents := []*ast.Identifier{}
+
+	if p.peekToken.Is(token.RPAREN) {
+		p.nextToken()
+		return idents
+	}
+
+	seen := make(map[string]bool)
+	for {
+		p.nextToken()
+
+		if !p.curToken.Is(token.IDENT) {
+			if !p.curToken.Is(token.ILLEGAL) {
+				d := diag.Errorf(diag.InvalidParameter, p.curToken.Span(),
+					"expected parameter name, got %s instead", p.curToken.Type())
+				d.Expected = []token.TokenType{token.IDENT}
+				d.Actual = p.curToken.Type()
+				p.report(d)
+			}
+			return nil
+		}
+
+		name := p.curToken.Literal()
+		if seen[name] {
+			p.errorf(diag.InvalidParameter, p.curToken.Span(), "duplicate parameter '%s'", name)
+			return nil
+		}
+		seen[name] = true
+		idents = append(idents, &ast.Identifier{Token: p.curToken, Value: name})
+
+		if !p.peekToken.Is(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.peekToken.Is(token.RPAREN) {
+		var hint string
+		if p.peekToken.Is(token.IDENT) {
+			hint = "parameters are separated by commas"
+		}
+		p.peekErrorWithHint(token.RPAREN, hint)
+		return nil
+	}
+	p.nextToken()
+
+	return idents
+}
+
+// parseCallExpression assumes curToken is the LPAREN following the function
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	expr := &ast.CallExpression{Token: p.curToken, Function: function}
+
+	expr.Arguments = p.parseCallArguments()
+	if expr.Arguments == nil {
+		return nil
+	}
+	expr.Rparen = p.curToken
+
+	return expr
+}
+
+// parseCallArguments assumes curToken is LPAREN, and leaves curToken on the
+// matching RPAREN. Returns nil if any argument fails to parse.
+func (p *Parser) parseCallArguments() []ast.Expression {
+	args := []ast.Expression{}
+
+	if p.peekToken.Is(token.RPAREN) {
+		p.nextToken()
+		return args
+	}
+
+	for {
+		p.nextToken()
+
+		arg := p.parseExpression(LOWEST)
+		if arg == nil {
+			return nil
+		}
+		args = append(args, arg)
+
+		if !p.peekToken.Is(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	return args
+}
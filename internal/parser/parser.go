@@ -1,71 +1,261 @@
 package parser
 
 import (
-	"fmt"
-
 	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/diag"
 	"github.com/danbrakeley/hai/internal/lexer"
 	"github.com/danbrakeley/hai/internal/token"
 )
 
+type (
+	prefixParseFn func() ast.Expression
+	infixParseFn  func(ast.Expression) ast.Expression
+)
+
+// MaxErrors is the default number of errors after which the parser gives up.
+const MaxErrors = 10
+
+// MaxNesting is how deeply expressions can nest, counting each operator and
+// call that an expression is built from. Everything that walks the syntax
+// tree does so recursively, so without a limit a large enough program could
+// exhaust the Go stack, which crashes the process.
+const MaxNesting = 10000
+
 type Parser struct {
 	lex       *lexer.Lexer
 	curToken  token.Token
 	peekToken token.Token
-	errors    []string
+	diags     []diag.Diagnostic
+	comments  []token.Token
+
+	maxErrors int
+	stopped   *diag.Diagnostic // set if parsing stopped at maxErrors
+
+	nesting int // of the expression being parsed
+
+	// resumeAtCur is set when recovering from an error leaves curToken on a
+	// closing brace that the failed statement did not use, so that the
+	// enclosing block can still be closed by it.
+	resumeAtCur bool
+
+	prefixParseFns map[token.TokenType]prefixParseFn
+	infixParseFns  map[token.TokenType]infixParseFn
+}
+
+type Option func(*Parser)
+
+// WithMaxErrors sets the number of errors, including those found by the
+// lexer, after which the parser stops. The default is MaxErrors, and 0 means
+// there is no limit.
+func WithMaxErrors(n int) Option {
+	return func(p *Parser) {
+		p.maxErrors = n
+	}
 }
 
-func New(l *lexer.Lexer) *Parser {
+func New(l *lexer.Lexer, opts ...Option) *Parser {
 	p := &Parser{
-		lex:    l,
-		errors: []string{},
+		lex:       l,
+		maxErrors: MaxErrors,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.prefixParseFns = map[token.TokenType]prefixParseFn{
+		token.IDENT:    p.parseIdentifier,
+		token.INT:      p.parseIntegerLiteral,
+		token.FLOAT:    p.parseFloatLiteral,
+		token.STRING:   p.parseStringLiteral,
+		token.TRUE:     p.parseBoolean,
+		token.FALSE:    p.parseBoolean,
+		token.BANG:     p.parsePrefixExpression,
+		token.MINUS:    p.parsePrefixExpression,
+		token.LPAREN:   p.parseGroupedExpression,
+		token.IF:       p.parseIfExpression,
+		token.FUNCTION: p.parseFunctionLiteral,
+	}
+
+	p.infixParseFns = make(map[token.TokenType]infixParseFn)
+	for typ := range precedences {
+		p.infixParseFns[typ] = p.parseInfixExpression
+	}
+	p.infixParseFns[token.LPAREN] = p.parseCallExpression
+
 	p.nextToken()
 	p.nextToken()
 	return p
 }
 
-func (p *Parser) Errors() []string {
-	return p.errors
+// Diagnostics returns the problems found by both the lexer and the parser,
+// in the order they appear in the source. If the parser gave up, this ends
+// with a note saying where.
+func (p *Parser) Diagnostics() []diag.Diagnostic {
+	lexDiags := p.lex.Diagnostics()
+	diags := make([]diag.Diagnostic, 0, len(lexDiags)+len(p.diags)+1)
+	diags = append(diags, lexDiags...)
+	diags = append(diags, p.diags...)
+	diag.Sort(diags)
+	if p.stopped != nil {
+		if len(diags) > p.maxErrors {
+			// the lexer may have read a little past where the parser stopped
+			diags = diags[:p.maxErrors]
+		}
+		diags = append(diags, *p.stopped)
+	}
+	return diags
+}
+
+func (p *Parser) report(d diag.Diagnostic) {
+	p.diags = append(p.diags, d)
+}
+
+// tooManyErrors reports if the parser should give up, and if so, records
+// where it did.
+func (p *Parser) tooManyErrors() bool {
+	if p.stopped != nil {
+		return true
+	}
+	if p.maxErrors <= 0 || len(p.lex.Diagnostics())+len(p.diags) < p.maxErrors {
+		return false
+	}
+	p.stopped = &diag.Diagnostic{
+		Severity: diag.Note,
+		Message:  "too many errors, stopped parsing here",
+		Span:     p.curToken.Span(),
+	}
+	return true
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type().String())
-	p.errors = append(p.errors, msg)
+	var hint string
+	if t == token.SEMICOLON {
+		hint = "statements end with a semicolon"
+	}
+	p.peekErrorWithHint(t, hint)
+}
+
+func (p *Parser) peekErrorWithHint(t token.TokenType, hint string) {
+	if p.peekToken.Is(token.ILLEGAL) {
+		// the lexer already reported this token
+		return
+	}
+	d := diag.Errorf(diag.UnexpectedToken, p.peekToken.Span(),
+		"expected next token to be %s, got %s instead", t, p.peekToken.Type())
+	d.Expected = []token.TokenType{t}
+	d.Actual = p.peekToken.Type()
+	d.Hint = hint
+	p.report(d)
+}
+
+func (p *Parser) errorf(code diag.Code, span token.Span, format string, a ...any) {
+	p.report(diag.Errorf(code, span, format, a...))
+}
+
+// Comments returns the comments that were skipped over while parsing. This
+// is only populated if the lexer was created with lexer.WithComments().
+func (p *Parser) Comments() []token.Token {
+	return p.comments
 }
 
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.lex.NextToken()
+	for p.peekToken.Is(token.COMMENT) {
+		p.comments = append(p.comments, p.peekToken)
+		p.peekToken = p.lex.NextToken()
+	}
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = make([]ast.Statement, 0, 64)
 
-	for !p.curToken.Is(token.EOF) {
+	for !p.curToken.Is(token.EOF) && !p.tooManyErrors() {
 		stmt := p.parseStatement()
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
+		// a closing brace left over from an error has already been reported
+		p.resumeAtCur = false
 		p.nextToken()
 	}
 
 	return program
 }
 
+// parseStatement parses the statement starting at curToken, and leaves
+// curToken on its last token. If the statement has errors, the rest of it is
+// skipped over, and it may be returned as an ast.BadStatement.
 func (p *Parser) parseStatement() ast.Statement {
+	start := p.curToken
 	switch p.curToken.Type() {
 	case token.LET:
-		return p.parseLetStatement()
+		if stmt := p.parseLetStatement(); stmt != nil {
+			return stmt
+		}
 	case token.RETURN:
-		return p.parseReturnStatement()
-	default:
+		if stmt := p.parseReturnStatement(); stmt != nil {
+			return stmt
+		}
+	case token.SEMICOLON:
+		// empty statement
+		return nil
+	case token.RBRACE:
+		p.errorf(diag.UnmatchedBrace, p.curToken.Span(), "unexpected '}' with no matching '{'")
 		return nil
+	default:
+		if stmt := p.parseExpressionStatement(); stmt != nil {
+			return stmt
+		}
+	}
+
+	end := p.synchronize()
+	return &ast.BadStatement{Token: start, End: end}
+}
+
+// synchronize skips over the rest of a statement that has an error, and
+// returns the position just past the last token skipped. It stops with
+// curToken on the semicolon that ends the statement, or on the last token
+// before the next let or return, a closing brace that is not part of the
+// statement, or the end of the input.
+func (p *Parser) synchronize() token.Pos {
+	if p.curToken.Is(token.RBRACE) && p.failedAt(p.curToken) {
+		// the closing brace was unexpected, so it must belong to an enclosing
+		// block, which still needs it
+		p.resumeAtCur = true
+		return p.curToken.Span().Start
 	}
+
+	depth := 0
+	for !p.curToken.Is(token.EOF) {
+		if depth == 0 {
+			if p.curToken.Is(token.SEMICOLON) {
+				break
+			}
+			switch p.peekToken.Type() {
+			case token.LET, token.RETURN, token.RBRACE, token.EOF:
+				return p.curToken.Span().End
+			}
+		}
+		p.nextToken()
+		switch p.curToken.Type() {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			depth--
+		}
+	}
+	return p.curToken.Span().Start
 }
 
-// parseLetStatement assumes curToken is LET
+// failedAt reports if the last error found by the parser was at tok.
+func (p *Parser) failedAt(tok token.Token) bool {
+	return len(p.diags) > 0 && p.diags[len(p.diags)-1].Span.Start == tok.Pos()
+}
+
+// parseLetStatement assumes curToken is LET. If the value has errors, it is
+// replaced by an ast.BadExpression, and the rest of the statement is skipped.
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
@@ -80,16 +270,33 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 		return nil
 	}
 	p.nextToken()
+	p.nextToken()
+
+	stmt.Value = p.parseValue()
+	if _, ok := stmt.Value.(*ast.BadExpression); ok {
+		return stmt
+	}
 
-	// TODO: parse expressions
-	p.skipTo(token.SEMICOLON)
 	if !p.expectPeek(token.SEMICOLON) {
-		return nil
+		p.synchronize()
+		return stmt
 	}
+	p.nextToken()
 
 	return stmt
 }
 
+// parseValue parses the expression at curToken, which is the value of a let
+// or return statement. If it has errors, the rest of the statement is skipped,
+// and an ast.BadExpression is returned.
+func (p *Parser) parseValue() ast.Expression {
+	start := p.curToken
+	if expr := p.parseExpression(LOWEST); expr != nil {
+		return expr
+	}
+	return &ast.BadExpression{Token: start, End: p.synchronize()}
+}
+
 func (p *Parser) expectPeek(t token.TokenType) bool {
 	if p.peekToken.Is(t) {
 		return true
@@ -98,21 +305,84 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 	return false
 }
 
-func (p *Parser) skipTo(t token.TokenType) {
-	for !p.peekToken.Is(t) && !p.peekToken.Is(token.EOF) {
-		p.nextToken()
-	}
-}
-
-// parseReturnStatement assumes curToken is RETURN
+// parseReturnStatement assumes curToken is RETURN. If the value has errors,
+// it is replaced by an ast.BadExpression, and the rest of the statement is
+// skipped.
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
+	p.nextToken()
+
+	stmt.ReturnValue = p.parseValue()
+	if _, ok := stmt.ReturnValue.(*ast.BadExpression); ok {
+		return stmt
+	}
 
-	// TODO: parse expressions
-	p.skipTo(token.SEMICOLON)
 	if !p.expectPeek(token.SEMICOLON) {
+		p.synchronize()
+		return stmt
+	}
+	p.nextToken()
+
+	return stmt
+}
+
+// parseExpressionStatement assumes curToken is the first token of an expression.
+// The trailing semicolon may be left off if the expression ends in a block, or
+// is the last thing before a closing brace or the end of the input.
+func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+	stmt := &ast.ExpressionStatement{Token: p.curToken}
+
+	stmt.Expression = p.parseExpression(LOWEST)
+	if stmt.Expression == nil {
 		return nil
 	}
 
+	switch {
+	case p.peekToken.Is(token.SEMICOLON):
+		p.nextToken()
+	case p.peekToken.Is(token.RBRACE), p.peekToken.Is(token.EOF):
+	case endsWithBlock(stmt.Expression):
+	default:
+		p.peekError(token.SEMICOLON)
+		p.synchronize()
+	}
+
 	return stmt
 }
+
+func endsWithBlock(expr ast.Expression) bool {
+	_, ok := expr.(*ast.IfExpression)
+	return ok
+}
+
+// parseBlockStatement assumes curToken is LBRACE, and leaves curToken on the
+// matching RBRACE
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.curToken}
+	block.Statements = []ast.Statement{}
+	p.nextToken()
+
+	for !p.curToken.Is(token.RBRACE) {
+		if p.tooManyErrors() {
+			return nil
+		}
+		if p.curToken.Is(token.EOF) {
+			d := diag.Errorf(diag.UnclosedDelimiter, block.Token.Span(), "unclosed '{'")
+			d.Hint = "expected a matching '}' before the end of the input"
+			p.report(d)
+			return nil
+		}
+		stmt := p.parseStatement()
+		if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+		if p.resumeAtCur {
+			p.resumeAtCur = false
+			continue
+		}
+		p.nextToken()
+	}
+	block.Rbrace = p.curToken
+
+	return block
+}
@@ -1,9 +1,13 @@
 package parser
 
 import (
+	"fmt"
+	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/diag"
 	"github.com/danbrakeley/hai/internal/lexer"
 )
 
@@ -12,25 +16,31 @@ func TestLetStatements(t *testing.T) {
 		name           string
 		input          string
 		expectedIdents []string
-		errors         []string
+		errors         []expectedDiag
 	}{
 		{
 			"single valid let",
 			"let x = 5;",
 			[]string{"x"},
-			[]string{},
+			nil,
 		},
 		{
 			"missing semicolon",
 			"let x = 5",
 			[]string{},
-			[]string{"expected next token to be semicolon, got eof instead"},
+			[]expectedDiag{{diag.UnexpectedToken, 1, 10}},
 		},
 		{
 			"missing identifier",
 			"let = 5;",
 			[]string{},
-			[]string{"expected next token to be ident, got assign instead"},
+			[]expectedDiag{{diag.UnexpectedToken, 1, 5}},
+		},
+		{
+			"malformed identifier",
+			"let 2a = 5;",
+			[]string{},
+			[]expectedDiag{{diag.MalformedNumber, 1, 5}},
 		},
 		{
 			"three valid lets",
@@ -39,7 +49,7 @@ let x = 5;
 let y = 10;
 let foobar = 838383;`,
 			[]string{"x", "y", "foobar"},
-			[]string{},
+			nil,
 		},
 		{
 			"three invalid lets",
@@ -48,10 +58,10 @@ let = 5;
 let y 10;
 let foobar = ;`,
 			[]string{},
-			[]string{
-				"expected next token to be ident, got assign instead",
-				"expected next token to be assign, got int instead",
-				// "expected next token to be int, got semicolon instead",
+			[]expectedDiag{
+				{diag.UnexpectedToken, 2, 5},    // expected ident, got assign
+				{diag.UnexpectedToken, 3, 7},    // expected assign, got int
+				{diag.MissingExpression, 4, 14}, // expected expression, got semicolon
 			},
 		},
 	}
@@ -63,7 +73,7 @@ let foobar = ;`,
 
 			program := p.ParseProgram()
 
-			errors := p.Errors()
+			errors := p.Diagnostics()
 			if !checkErrors(t, errors, tc.errors) {
 				return
 			}
@@ -105,26 +115,26 @@ func TestReturnStatements(t *testing.T) {
 		name          string
 		input         string
 		expectedCount int
-		errors        []string
+		errors        []expectedDiag
 	}{
 		{
 			"single valid return",
 			"return 5;",
 			1,
-			[]string{},
+			nil,
 		},
 		{
 			"missing semicolon",
 			"return 5",
 			0,
-			[]string{"expected next token to be semicolon, got eof instead"},
+			[]expectedDiag{{diag.UnexpectedToken, 1, 9}},
+		},
+		{
+			"missing expression",
+			"return ;",
+			0,
+			[]expectedDiag{{diag.MissingExpression, 1, 8}},
 		},
-		// {
-		// 	"missing expression",
-		// 	"return ;",
-		// 	0,
-		// 	[]string{"expected next token to be int, got semicolon instead",},
-		// },
 		{
 			"three valid returns",
 			`
@@ -132,7 +142,7 @@ return 5;
 return 10;
 return 993322;`,
 			3,
-			[]string{},
+			nil,
 		},
 		{
 			"three invalid returns",
@@ -140,12 +150,11 @@ return 993322;`,
 return ;
 return 10
 return return;`,
-			// 0,
-			2,
-			[]string{
-				// "expected next token to be int, got semicolon instead",
-				// "expected next token to be semicolon, got return instead",
-				// "expected next token to be int, got return instead",
+			0,
+			[]expectedDiag{
+				{diag.MissingExpression, 2, 8}, // expected expression, got semicolon
+				{diag.UnexpectedToken, 4, 1},   // expected semicolon, got return
+				{diag.MissingExpression, 4, 8}, // expected expression, got return
 			},
 		},
 	}
@@ -157,7 +166,7 @@ return return;`,
 
 			program := p.ParseProgram()
 
-			errors := p.Errors()
+			errors := p.Diagnostics()
 			if !checkErrors(t, errors, tc.errors) {
 				return
 			}
@@ -174,7 +183,14 @@ return return;`,
 	}
 }
 
-func checkErrors(t *testing.T, actual, expected []string) bool {
+// expectedDiag is the code and starting line and column of a diagnostic
+type expectedDiag struct {
+	code   diag.Code
+	line   int
+	column int
+}
+
+func checkErrors(t *testing.T, actual []diag.Diagnostic, expected []expectedDiag) bool {
 	t.Helper()
 	if len(actual) != len(expected) {
 		var msg string
@@ -183,7 +199,7 @@ func checkErrors(t *testing.T, actual, expected []string) bool {
 		} else {
 			msg = "expected parse errors:\n"
 			for _, e := range expected {
-				msg += "\t" + e + "\n"
+				msg += fmt.Sprintf("\t%d:%d: %s\n", e.line, e.column, e.code)
 			}
 		}
 		if len(actual) == 0 {
@@ -191,16 +207,640 @@ func checkErrors(t *testing.T, actual, expected []string) bool {
 		} else {
 			msg += "got:\n"
 			for _, e := range actual {
-				msg += "\t" + e + "\n"
+				msg += "\t" + e.Error() + "\n"
 			}
 		}
 		t.Error(msg)
 	} else {
 		for i := range actual {
-			if actual[i] != expected[i] {
-				t.Errorf("expected error %d to be:\n\t%s\ngot:\n\t%s", i, expected[i], actual[i])
+			a, e := actual[i], expected[i]
+			if a.Code != e.code || a.Span.Start.Line != e.line || a.Span.Start.Column != e.column {
+				t.Errorf("expected error %d to be:\n\t%d:%d: %s\ngot:\n\t%s", i, e.line, e.column, e.code, a.Error())
 			}
 		}
 	}
 	return len(actual) == 0
 }
+
+func TestLiteralExpressions(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected any
+	}{
+		{"foobar;", "foobar"},
+		{"5;", int64(5)},
+		{"true;", true},
+		{"false;", false},
+		{"9223372036854775807;", int64(9223372036854775807)},
+		{"0xff;", int64(255)},
+		{"1_000;", int64(1000)},
+		{"1.5e3;", 1500.0},
+		{"9223372036854775808;", bigInt(t, "9223372036854775808")},
+		{"0x1_0000_0000_0000_0000;", bigInt(t, "18446744073709551616")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			stmt := parseSingleExpressionStatement(t, tc.input)
+			testLiteralExpression(t, stmt.Expression, tc.expected)
+		})
+	}
+}
+
+func TestLetAndReturnValues(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected any
+	}{
+		{"let x = 5;", int64(5)},
+		{"let y = true;", true},
+		{"let foobar = y;", "y"},
+		{"return 5;", int64(5)},
+		{"return false;", false},
+		{"return foobar;", "foobar"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			program := parseValidProgram(t, tc.input)
+			if len(program.Statements) != 1 {
+				t.Fatalf("expected 1 statement, got %d", len(program.Statements))
+			}
+
+			switch stmt := program.Statements[0].(type) {
+			case *ast.LetStatement:
+				testLiteralExpression(t, stmt.Value, tc.expected)
+			case *ast.ReturnStatement:
+				testLiteralExpression(t, stmt.ReturnValue, tc.expected)
+			default:
+				t.Fatalf("expected let or return statement, got %T", stmt)
+			}
+		})
+	}
+}
+
+func TestPrefixExpressions(t *testing.T) {
+	cases := []struct {
+		input    string
+		operator string
+		value    any
+	}{
+		{"!5;", "!", int64(5)},
+		{"-15;", "-", int64(15)},
+		{"!foobar;", "!", "foobar"},
+		{"-foobar;", "-", "foobar"},
+		{"!true;", "!", true},
+		{"!false;", "!", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			stmt := parseSingleExpressionStatement(t, tc.input)
+
+			expr, ok := stmt.Expression.(*ast.PrefixExpression)
+			if !ok {
+				t.Fatalf("expected *ast.PrefixExpression, got %T", stmt.Expression)
+			}
+			if expr.Operator != tc.operator {
+				t.Fatalf("expected operator '%s', got '%s'", tc.operator, expr.Operator)
+			}
+			testLiteralExpression(t, expr.Right, tc.value)
+		})
+	}
+}
+
+func TestInfixExpressions(t *testing.T) {
+	cases := []struct {
+		input    string
+		left     any
+		operator string
+		right    any
+	}{
+		{"5 + 5;", int64(5), "+", int64(5)},
+		{"5 - 5;", int64(5), "-", int64(5)},
+		{"5 * 5;", int64(5), "*", int64(5)},
+		{"5 / 5;", int64(5), "/", int64(5)},
+		{"5 > 5;", int64(5), ">", int64(5)},
+		{"5 < 5;", int64(5), "<", int64(5)},
+		{"5 == 5;", int64(5), "==", int64(5)},
+		{"5 != 5;", int64(5), "!=", int64(5)},
+		{"foobar + barfoo;", "foobar", "+", "barfoo"},
+		{"true == true", true, "==", true},
+		{"true != false", true, "!=", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			stmt := parseSingleExpressionStatement(t, tc.input)
+			testInfixExpression(t, stmt.Expression, tc.left, tc.operator, tc.right)
+		})
+	}
+}
+
+func TestOperatorPrecedence(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"-a * b", "((-a) * b)"},
+		{"!-a", "(!(-a))"},
+		{"a + b + c", "((a + b) + c)"},
+		{"a + b - c", "((a + b) - c)"},
+		{"a * b * c", "((a * b) * c)"},
+		{"a * b / c", "((a * b) / c)"},
+		{"a + b / c", "(a + (b / c))"},
+		{"a + b * c + d / e - f", "(((a + (b * c)) + (d / e)) - f)"},
+		{"3 + 4; -5 * 5", "(3 + 4)((-5) * 5)"},
+		{"5 > 4 == 3 < 4", "((5 > 4) == (3 < 4))"},
+		{"5 < 4 != 3 > 4", "((5 < 4) != (3 > 4))"},
+		{"3 + 4 * 5 == 3 * 1 + 4 * 5", "((3 + (4 * 5)) == ((3 * 1) + (4 * 5)))"},
+		{"true", "true"},
+		{"3 > 5 == false", "((3 > 5) == false)"},
+		{"1 + (2 + 3) + 4", "((1 + (2 + 3)) + 4)"},
+		{"(5 + 5) * 2", "((5 + 5) * 2)"},
+		{"2 / (5 + 5)", "(2 / (5 + 5))"},
+		{"-(5 + 5)", "(-(5 + 5))"},
+		{"!(true == true)", "(!(true == true))"},
+		{"a + add(b * c) + d", "((a + add((b * c))) + d)"},
+		{"add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))", "add(a, b, 1, (2 * 3), (4 + 5), add(6, (7 * 8)))"},
+		{"add(a + b + c * d / f + g)", "add((((a + b) + ((c * d) / f)) + g))"},
+		{"-f(x)", "(-f(x))"},
+		{"fn(x) { x }(5)", "fn(x) { x; }(5)"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			program := parseValidProgram(t, tc.input)
+
+			var actual string
+			for _, stmt := range program.Statements {
+				es, ok := stmt.(*ast.ExpressionStatement)
+				if !ok {
+					t.Fatalf("expected *ast.ExpressionStatement, got %T", stmt)
+				}
+				actual += es.Expression.String()
+			}
+			if actual != tc.expected {
+				t.Errorf("expected '%s', got '%s'", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		first expectedDiag
+	}{
+		{"missing operand", "5 + ;", expectedDiag{diag.MissingExpression, 1, 5}},
+		{"unclosed paren", "(5 + 5;", expectedDiag{diag.UnexpectedToken, 1, 7}},
+		{"missing semicolon", "5 5", expectedDiag{diag.UnexpectedToken, 1, 3}},
+		{"float too large", "x + 1e400;", expectedDiag{diag.InvalidFloat, 1, 5}},
+		{"if without parens", "if x { 1 }", expectedDiag{diag.UnexpectedToken, 1, 4}},
+		{"if without braces", "if (x) 1;", expectedDiag{diag.UnexpectedToken, 1, 8}},
+		{"unclosed if block", "if (x) {\n  1;\n", expectedDiag{diag.UnclosedDelimiter, 1, 8}},
+		{"unclosed else block", "if (x) { 1 } else { 2", expectedDiag{diag.UnclosedDelimiter, 1, 19}},
+		{"unclosed fn body", "let f = fn(x) { x;", expectedDiag{diag.UnclosedDelimiter, 1, 15}},
+		{"unmatched brace", "let x = 1; }", expectedDiag{diag.UnmatchedBrace, 1, 12}},
+		{"param is not an ident", "fn(x, 1) { x }", expectedDiag{diag.InvalidParameter, 1, 7}},
+		{"trailing comma in params", "fn(x,) { x }", expectedDiag{diag.InvalidParameter, 1, 6}},
+		{"missing comma in params", "fn(x y) { x }", expectedDiag{diag.UnexpectedToken, 1, 6}},
+		{"duplicate param", "fn(x, y, x) { x }", expectedDiag{diag.InvalidParameter, 1, 10}},
+		{"unclosed params", "fn(x, y { x }", expectedDiag{diag.UnexpectedToken, 1, 9}},
+		{"unclosed call", "add(1, 2;", expectedDiag{diag.UnexpectedToken, 1, 9}},
+		{"missing call argument", "add(1, );", expectedDiag{diag.MissingExpression, 1, 8}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := New(lexer.New(tc.input))
+			p.ParseProgram()
+
+			// only the first error is checked, as follow-on errors are not the point
+			diags := p.Diagnostics()
+			if len(diags) == 0 {
+				t.Fatalf("expected error %d:%d: %s, got none", tc.first.line, tc.first.column, tc.first.code)
+			}
+			checkErrors(t, diags[:1], []expectedDiag{tc.first})
+		})
+	}
+}
+
+func parseValidProgram(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	if !checkErrors(t, p.Diagnostics(), nil) {
+		t.FailNow()
+	}
+	return program
+}
+
+func parseSingleExpressionStatement(t *testing.T, input string) *ast.ExpressionStatement {
+	t.Helper()
+	program := parseValidProgram(t, input)
+	if len(program.Statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("expected *ast.ExpressionStatement, got %T", program.Statements[0])
+	}
+	return stmt
+}
+
+func testLiteralExpression(t *testing.T, expr ast.Expression, expected any) {
+	t.Helper()
+	switch v := expected.(type) {
+	case int64:
+		il, ok := expr.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("expected *ast.IntegerLiteral, got %T", expr)
+		}
+		if il.Value != v || il.Big != nil {
+			t.Errorf("expected value %d, got %s", v, il)
+		}
+	case *big.Int:
+		il, ok := expr.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("expected *ast.IntegerLiteral, got %T", expr)
+		}
+		if il.Big == nil || il.Big.Cmp(v) != 0 {
+			t.Errorf("expected value %s, got %s", v, il)
+		}
+	case float64:
+		fl, ok := expr.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("expected *ast.FloatLiteral, got %T", expr)
+		}
+		if fl.Value != v {
+			t.Errorf("expected value %g, got %g", v, fl.Value)
+		}
+	case bool:
+		b, ok := expr.(*ast.Boolean)
+		if !ok {
+			t.Fatalf("expected *ast.Boolean, got %T", expr)
+		}
+		if b.Value != v {
+			t.Errorf("expected value %t, got %t", v, b.Value)
+		}
+	case string:
+		ident, ok := expr.(*ast.Identifier)
+		if !ok {
+			t.Fatalf("expected *ast.Identifier, got %T", expr)
+		}
+		if ident.Value != v {
+			t.Errorf("expected value '%s', got '%s'", v, ident.Value)
+		}
+		if ident.TokenLiteral() != v {
+			t.Errorf("expected TokenLiteral() '%s', got '%s'", v, ident.TokenLiteral())
+		}
+	default:
+		t.Fatalf("unhandled type for expected value: %T", expected)
+	}
+}
+
+func bigInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+	b, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("invalid big.Int %q", s)
+	}
+	return b
+}
+
+func testInfixExpression(t *testing.T, expr ast.Expression, left any, operator string, right any) {
+	t.Helper()
+	ie, ok := expr.(*ast.InfixExpression)
+	if !ok {
+		t.Fatalf("expected *ast.InfixExpression, got %T", expr)
+	}
+	testLiteralExpression(t, ie.Left, left)
+	if ie.Operator != operator {
+		t.Errorf("expected operator '%s', got '%s'", operator, ie.Operator)
+	}
+	testLiteralExpression(t, ie.Right, right)
+}
+
+func TestIfExpression(t *testing.T) {
+	stmt := parseSingleExpressionStatement(t, "if (x < y) { x }")
+
+	expr, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("expected *ast.IfExpression, got %T", stmt.Expression)
+	}
+	testInfixExpression(t, expr.Condition, "x", "<", "y")
+
+	if len(expr.Consequence.Statements) != 1 {
+		t.Fatalf("expected 1 consequence statement, got %d", len(expr.Consequence.Statements))
+	}
+	consequence, ok := expr.Consequence.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("expected *ast.ExpressionStatement, got %T", expr.Consequence.Statements[0])
+	}
+	testLiteralExpression(t, consequence.Expression, "x")
+
+	if expr.Alternative != nil {
+		t.Errorf("expected no alternative, got %+v", expr.Alternative)
+	}
+}
+
+func TestIfElseExpression(t *testing.T) {
+	program := parseValidProgram(t, "if (x < y) { x; } else { let z = y; z }\nlet a = 1;")
+	if len(program.Statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("expected *ast.ExpressionStatement, got %T", program.Statements[0])
+	}
+	expr, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("expected *ast.IfExpression, got %T", stmt.Expression)
+	}
+	testInfixExpression(t, expr.Condition, "x", "<", "y")
+
+	if len(expr.Consequence.Statements) != 1 {
+		t.Fatalf("expected 1 consequence statement, got %d", len(expr.Consequence.Statements))
+	}
+	if expr.Alternative == nil {
+		t.Fatalf("expected an alternative")
+	}
+	if len(expr.Alternative.Statements) != 2 {
+		t.Fatalf("expected 2 alternative statements, got %d", len(expr.Alternative.Statements))
+	}
+	if _, ok := expr.Alternative.Statements[0].(*ast.LetStatement); !ok {
+		t.Errorf("expected *ast.LetStatement, got %T", expr.Alternative.Statements[0])
+	}
+}
+
+func TestFunctionLiteral(t *testing.T) {
+	stmt := parseSingleExpressionStatement(t, "fn(x, y) { x + y; }")
+
+	fl, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("expected *ast.FunctionLiteral, got %T", stmt.Expression)
+	}
+	if len(fl.Parameters) != 2 {
+		t.Fatalf("expected 2 parameters, got %d", len(fl.Parameters))
+	}
+	testLiteralExpression(t, fl.Parameters[0], "x")
+	testLiteralExpression(t, fl.Parameters[1], "y")
+
+	if len(fl.Body.Statements) != 1 {
+		t.Fatalf("expected 1 body statement, got %d", len(fl.Body.Statements))
+	}
+	body, ok := fl.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("expected *ast.ExpressionStatement, got %T", fl.Body.Statements[0])
+	}
+	testInfixExpression(t, body.Expression, "x", "+", "y")
+}
+
+func TestFunctionParameters(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{"fn() {};", []string{}},
+		{"fn(x) {};", []string{"x"}},
+		{"fn(x, y, z) {};", []string{"x", "y", "z"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			stmt := parseSingleExpressionStatement(t, tc.input)
+			fl, ok := stmt.Expression.(*ast.FunctionLiteral)
+			if !ok {
+				t.Fatalf("expected *ast.FunctionLiteral, got %T", stmt.Expression)
+			}
+			if len(fl.Parameters) != len(tc.expected) {
+				t.Fatalf("expected %d parameters, got %d", len(tc.expected), len(fl.Parameters))
+			}
+			for i, ident := range tc.expected {
+				testLiteralExpression(t, fl.Parameters[i], ident)
+			}
+		})
+	}
+}
+
+func TestCallExpression(t *testing.T) {
+	stmt := parseSingleExpressionStatement(t, "add(1, 2 * 3, 4 + 5);")
+
+	expr, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("expected *ast.CallExpression, got %T", stmt.Expression)
+	}
+	testLiteralExpression(t, expr.Function, "add")
+
+	if len(expr.Arguments) != 3 {
+		t.Fatalf("expected 3 arguments, got %d", len(expr.Arguments))
+	}
+	testLiteralExpression(t, expr.Arguments[0], int64(1))
+	testInfixExpression(t, expr.Arguments[1], int64(2), "*", int64(3))
+	testInfixExpression(t, expr.Arguments[2], int64(4), "+", int64(5))
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{`"hello world";`, "hello world"},
+		{`"tab\there";`, "tab\there"},
+		{"`raw\\t`;", `raw\t`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			stmt := parseSingleExpressionStatement(t, tc.input)
+
+			literal, ok := stmt.Expression.(*ast.StringLiteral)
+			if !ok {
+				t.Fatalf("expected *ast.StringLiteral, got %T", stmt.Expression)
+			}
+			if literal.Value != tc.expected {
+				t.Errorf("expected value %q, got %q", tc.expected, literal.Value)
+			}
+		})
+	}
+}
+
+func TestStringErrorsAreNotRepeated(t *testing.T) {
+	p := New(lexer.New(`let s = "abc`))
+	p.ParseProgram()
+	checkErrors(t, p.Diagnostics(), []expectedDiag{{diag.UnterminatedString, 1, 9}})
+}
+
+func TestCommentsAreSkipped(t *testing.T) {
+	input := `// add things
+let add = fn(x, /* the other thing */ y) {
+  x + y // no semicolon needed
+};`
+
+	p := New(lexer.New(input, lexer.WithComments()))
+	program := p.ParseProgram()
+	if !checkErrors(t, p.Diagnostics(), nil) {
+		t.FailNow()
+	}
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(program.Statements))
+	}
+
+	comments := p.Comments()
+	expected := []string{"// add things", "/* the other thing */", "// no semicolon needed"}
+	if len(comments) != len(expected) {
+		t.Fatalf("expected %d comments, got %d", len(expected), len(comments))
+	}
+	for i, e := range expected {
+		if comments[i].Literal() != e {
+			t.Errorf("expected comment %d to be '%s', got '%s'", i, e, comments[i].Literal())
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		errors []expectedDiag
+		tree   string // String of the program
+	}{
+		{
+			"skips to semicolon",
+			"let = 5 + 6; let y = 2;",
+			[]expectedDiag{{diag.UnexpectedToken, 1, 5}},
+			"<bad statement> let y = 2;",
+		},
+		{
+			"bad value",
+			"let x = * 2; return ;",
+			[]expectedDiag{{diag.MissingExpression, 1, 9}, {diag.MissingExpression, 1, 21}},
+			"let x = <bad expression>; return <bad expression>;",
+		},
+		{
+			"missing semicolon before let",
+			"let x = 1 let y = 2;",
+			[]expectedDiag{{diag.UnexpectedToken, 1, 11}},
+			"let x = 1; let y = 2;",
+		},
+		{
+			"skips nested blocks",
+			"let = fn() { let a = 1; }; x",
+			[]expectedDiag{{diag.UnexpectedToken, 1, 5}},
+			"<bad statement> x;",
+		},
+		{
+			"recovers inside a block",
+			"let f = fn() { let = 1; 2 }; f",
+			[]expectedDiag{{diag.UnexpectedToken, 1, 20}},
+			"let f = fn() { <bad statement> 2; }; f;",
+		},
+		{
+			"error at closing brace",
+			"if (x) { 1 + } 2",
+			[]expectedDiag{{diag.MissingExpression, 1, 14}},
+			"if (x) { <bad statement> }; 2;",
+		},
+		{
+			"stray closing brace",
+			"1 + }; 2",
+			[]expectedDiag{{diag.MissingExpression, 1, 5}},
+			"<bad statement> 2;",
+		},
+		{
+			"stops at return",
+			"f(1, 2 return 3;",
+			[]expectedDiag{{diag.UnexpectedToken, 1, 8}},
+			"<bad statement> return 3;",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := New(lexer.New(tc.input))
+			program := p.ParseProgram()
+			checkErrors(t, p.Diagnostics(), tc.errors)
+			if program.String() != tc.tree {
+				t.Errorf("expected program %q, got %q", tc.tree, program.String())
+			}
+		})
+	}
+}
+
+func TestBadNodeSpans(t *testing.T) {
+	p := New(lexer.New("let = 1 + 2;\nlet x = 3 *;"))
+	program := p.ParseProgram()
+	if len(program.Statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(program.Statements))
+	}
+
+	bad, ok := program.Statements[0].(*ast.BadStatement)
+	if !ok {
+		t.Fatalf("expected *ast.BadStatement, got %T", program.Statements[0])
+	}
+	if span := ast.Span(bad); span.Start.Offset != 0 || span.End.Offset != 11 {
+		t.Errorf("expected bad statement to cover 0-11, got %d-%d", span.Start.Offset, span.End.Offset)
+	}
+
+	let := program.Statements[1].(*ast.LetStatement)
+	if span := ast.Span(let.Value); span.Start.Offset != 21 || span.End.Offset != 24 {
+		t.Errorf("expected bad expression to cover 21-24, got %d-%d", span.Start.Offset, span.End.Offset)
+	}
+}
+
+func TestMaxNesting(t *testing.T) {
+	cases := []struct {
+		input string
+		ok    bool
+	}{
+		{strings.Repeat("-", MaxNesting-1) + "1", true},
+		{strings.Repeat("-", MaxNesting) + "1", false},
+		{strings.Repeat("-", 100_000) + "1", false},
+		{strings.Repeat("(", MaxNesting) + "1" + strings.Repeat(")", MaxNesting), false},
+		{strings.Repeat("1 + ", MaxNesting-1) + "1", true},
+		{strings.Repeat("1 + ", 100_000) + "1", false},
+		{"f" + strings.Repeat("()", 100_000), false},
+		{strings.Repeat("fn() { ", MaxNesting) + strings.Repeat("}", MaxNesting), true},
+		{strings.Repeat("fn() { ", MaxNesting+1) + strings.Repeat("}", MaxNesting+1), false},
+	}
+
+	for _, tc := range cases {
+		p := New(lexer.New(tc.input))
+		p.ParseProgram()
+		diags := p.Diagnostics()
+		if tc.ok {
+			if len(diags) > 0 {
+				t.Errorf("%.20s...: unexpected error: %s", tc.input, diags[0].Error())
+			}
+			continue
+		}
+		if len(diags) != 1 || diags[0].Code != diag.NestedTooDeeply {
+			t.Errorf("%.20s...: expected one %s diagnostic, got %v", tc.input, diag.NestedTooDeeply, diags)
+		}
+	}
+}
+
+func TestMaxErrors(t *testing.T) {
+	input := strings.Repeat("let = 1;\n", 20)
+
+	p := New(lexer.New(input))
+	p.ParseProgram()
+	diags := p.Diagnostics()
+	if len(diags) != MaxErrors+1 {
+		t.Fatalf("expected %d diagnostics, got %d", MaxErrors+1, len(diags))
+	}
+	last := diags[len(diags)-1]
+	if last.Severity != diag.Note || last.Span.Start.Line != MaxErrors+1 {
+		t.Errorf("expected a note on line %d, got %s", MaxErrors+1, last.Error())
+	}
+
+	p = New(lexer.New(input), WithMaxErrors(0))
+	p.ParseProgram()
+	if len(p.Diagnostics()) != 20 {
+		t.Errorf("expected 20 diagnostics without a limit, got %d", len(p.Diagnostics()))
+	}
+}
@@ -0,0 +1,327 @@
+// Package printer writes syntax trees out as source code, one statement per
+// line, with blocks indented, and with only the parentheses that the
+// precedence of the operators requires.
+package printer
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/numlit"
+	"github.com/danbrakeley/hai/internal/parser"
+	"github.com/danbrakeley/hai/internal/strlit"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// Indent is the text used for each level of indentation.
+const Indent = "    "
+
+// Fprint writes node to w as source code.
+func Fprint(w io.Writer, node ast.Node) error {
+	_, err := io.WriteString(w, Sprint(node))
+	return err
+}
+
+// Sprint returns node as source code. A program ends with a newline, other
+// nodes do not. If node was parsed from source, single blank lines between
+// statements are kept.
+func Sprint(node ast.Node) string {
+	return SprintWithComments(node, nil)
+}
+
+// FprintWithComments is like Fprint, but also writes out comments.
+func FprintWithComments(w io.Writer, node ast.Node, comments []token.Token) error {
+	_, err := io.WriteString(w, SprintWithComments(node, comments))
+	return err
+}
+
+// SprintWithComments is like Sprint, but also writes out comments, as
+// returned by parser.Comments for the source node was parsed from. Comments
+// are written between the statements nearest to where they were found, either
+// on their own lines or at the end of the line of the statement before them.
+func SprintWithComments(node ast.Node, comments []token.Token) string {
+	p := &printer{comments: comments}
+	switch node := node.(type) {
+	case *ast.Program:
+		p.statements(node.Statements, math.MaxInt)
+	case ast.Statement:
+		p.statement(node)
+	case ast.Expression:
+		p.expression(node, parser.LOWEST)
+	}
+	return p.sb.String()
+}
+
+type printer struct {
+	sb       strings.Builder
+	indent   int
+	comments []token.Token // not yet written, in source order
+	lastLine int           // source line of the last thing written, 0 at the start of a block
+}
+
+func (p *printer) write(s string) {
+	p.sb.WriteString(s)
+}
+
+func (p *printer) newline() {
+	p.sb.WriteByte('\n')
+}
+
+func (p *printer) writeIndent() {
+	p.write(strings.Repeat(Indent, p.indent))
+}
+
+// separate writes a blank line if there was at least one between the last
+// thing written and the given source line.
+func (p *printer) separate(line int) {
+	if p.lastLine > 0 && line > p.lastLine+1 {
+		p.newline()
+	}
+}
+
+// statements writes each statement on its own line, along with the comments
+// that come before limit, the offset of whatever follows the statements.
+func (p *printer) statements(stmts []ast.Statement, limit int) {
+	for i, s := range stmts {
+		next := limit
+		if i+1 < len(stmts) {
+			next = ast.Span(stmts[i+1]).Start.Offset
+		}
+
+		span := ast.Span(s)
+		p.leadingComments(span.Start.Offset)
+		p.separate(span.Start.Line)
+		p.statement(s)
+		if isBareIf(s) && i+1 < len(stmts) && continuesExpression(stmts[i+1]) {
+			// without the semicolon, the next line would be parsed as part of
+			// the if expression
+			p.write(";")
+		}
+		p.lastLine = span.End.Line
+		p.trailingComments(span.End, next)
+		p.newline()
+	}
+	p.leadingComments(limit)
+}
+
+// leadingComments writes each comment that starts before offset on its own
+// line.
+func (p *printer) leadingComments(offset int) {
+	for len(p.comments) > 0 && p.comments[0].Pos().Offset < offset {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+		p.separate(c.Pos().Line)
+		p.writeIndent()
+		p.write(commentText(c))
+		p.newline()
+		p.lastLine = c.Span().End.Line
+	}
+}
+
+// trailingComments writes, after the statement that ends at end, the comments
+// that are inside the statement or that start on the line it ends on, up to
+// limit.
+func (p *printer) trailingComments(end token.Pos, limit int) {
+	afterLine := false
+	for len(p.comments) > 0 {
+		c := p.comments[0]
+		inside := c.Pos().Offset < end.Offset
+		sameLine := c.Pos().Line == end.Line && c.Pos().Offset < limit
+		if !inside && !sameLine {
+			return
+		}
+		p.comments = p.comments[1:]
+		if afterLine {
+			// nothing can follow a line comment on the same line
+			p.newline()
+			p.writeIndent()
+		} else {
+			p.write(" ")
+		}
+		p.write(commentText(c))
+		// once a line comment has pushed the comments onto their own lines,
+		// the rest stay there too, so that formatting again changes nothing
+		afterLine = afterLine || c.Is(token.COMMENT) && strings.HasPrefix(c.Literal(), "//")
+		p.lastLine = max(p.lastLine, c.Span().End.Line)
+	}
+}
+
+// commentText returns the comment as it is written.
+func commentText(c token.Token) string {
+	return strings.TrimRight(c.Literal(), " \t\r")
+}
+
+func (p *printer) statement(s ast.Statement) {
+	p.writeIndent()
+	switch s := s.(type) {
+	case *ast.LetStatement:
+		p.write("let " + s.Name.Value + " = ")
+		p.expression(s.Value, parser.LOWEST)
+		p.write(";")
+	case *ast.ReturnStatement:
+		p.write("return ")
+		p.expression(s.ReturnValue, parser.LOWEST)
+		p.write(";")
+	case *ast.ExpressionStatement:
+		p.expression(s.Expression, parser.LOWEST)
+		if !isBareIf(s) {
+			p.write(";")
+		}
+	case *ast.BlockStatement:
+		p.block(s)
+	default:
+		p.write(s.String())
+	}
+}
+
+// block writes a block, with its statements indented on their own lines.
+// The caller is responsible for any indentation before the opening brace.
+func (p *printer) block(b *ast.BlockStatement) {
+	end := b.Rbrace.Pos().Offset
+	if len(b.Statements) == 0 && !p.hasCommentBefore(end) {
+		p.write("{}")
+		return
+	}
+	p.write("{")
+	p.newline()
+	p.indent++
+	p.lastLine = 0
+	p.statements(b.Statements, end)
+	p.indent--
+	p.writeIndent()
+	p.write("}")
+}
+
+// literal writes a literal as it was written in the source, so that raw
+// strings stay raw, or as canonical if the node was not parsed from source.
+func (p *printer) literal(tok token.Token, canonical string) {
+	if tok.Literal() != "" {
+		p.write(tok.Literal())
+	} else {
+		p.write(canonical)
+	}
+}
+
+func (p *printer) hasCommentBefore(offset int) bool {
+	return len(p.comments) > 0 && p.comments[0].Pos().Offset < offset
+}
+
+// expression writes e, in parentheses if its own precedence is lower than
+// the given precedence.
+func (p *printer) expression(e ast.Expression, precedence int) {
+	own := precedenceOf(e)
+	if own < precedence {
+		p.write("(")
+		defer p.write(")")
+	}
+
+	switch e := e.(type) {
+	case *ast.Identifier:
+		p.write(e.Value)
+	case *ast.IntegerLiteral:
+		p.literal(e.Token, e.String())
+	case *ast.FloatLiteral:
+		p.literal(e.Token, numlit.FormatFloat(e.Value))
+	case *ast.StringLiteral:
+		p.literal(e.Token, strlit.Quote(e.Value))
+	case *ast.Boolean:
+		p.write(strconv.FormatBool(e.Value))
+	case *ast.PrefixExpression:
+		p.write(e.Operator)
+		p.expression(e.Right, parser.PREFIX)
+	case *ast.InfixExpression:
+		// operators are left associative, so the right operand needs
+		// parentheses if it has the same precedence
+		p.expression(e.Left, own)
+		p.write(" " + e.Operator + " ")
+		p.expression(e.Right, own+1)
+	case *ast.IfExpression:
+		p.write("if (")
+		p.expression(e.Condition, parser.LOWEST)
+		p.write(") ")
+		p.block(e.Consequence)
+		if e.Alternative != nil {
+			p.write(" else ")
+			p.block(e.Alternative)
+		}
+	case *ast.FunctionLiteral:
+		p.write("fn(")
+		for i, param := range e.Parameters {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.write(param.Value)
+		}
+		p.write(") ")
+		p.block(e.Body)
+	case *ast.CallExpression:
+		p.expression(e.Function, parser.CALL)
+		p.write("(")
+		for i, arg := range e.Arguments {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expression(arg, parser.LOWEST)
+		}
+		p.write(")")
+	default:
+		p.write(e.String())
+	}
+}
+
+// precedenceOf returns how tightly e binds, with anything that is not an
+// operator binding tighter than any operator.
+func precedenceOf(e ast.Expression) int {
+	switch e := e.(type) {
+	case *ast.InfixExpression:
+		return parser.Precedence(e.Token.Type())
+	case *ast.PrefixExpression:
+		return parser.PREFIX
+	case *ast.CallExpression:
+		return parser.CALL
+	default:
+		return parser.CALL + 1
+	}
+}
+
+// isBareIf reports if s is an if expression on its own, which is written
+// without a trailing semicolon.
+func isBareIf(s ast.Statement) bool {
+	es, ok := s.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	_, ok = es.Expression.(*ast.IfExpression)
+	return ok
+}
+
+// continuesExpression reports if s, as written, starts with "(" or "-", which
+// the parser would take as a call or subtraction of whatever came before it.
+func continuesExpression(s ast.Statement) bool {
+	es, ok := s.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	e := es.Expression
+	for {
+		switch x := e.(type) {
+		case *ast.InfixExpression:
+			if precedenceOf(x.Left) < precedenceOf(x) {
+				return true
+			}
+			e = x.Left
+		case *ast.CallExpression:
+			if precedenceOf(x.Function) < parser.CALL {
+				return true
+			}
+			e = x.Function
+		case *ast.PrefixExpression:
+			return x.Operator == "-"
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package printer
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/parser"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+func TestSprint(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"let x=1+2*3;", "let x = 1 + 2 * 3;\n"},
+		{"(1 + 2) * 3", "(1 + 2) * 3;\n"},
+		{"1 - (2 - 3); (1 - 2) - 3", "1 - (2 - 3);\n1 - 2 - 3;\n"},
+		{"-(a + b); -f(x); (-f)(x); !-a", "-(a + b);\n-f(x);\n(-f)(x);\n!-a;\n"},
+		{"(a < b) == (c > d)", "a < b == c > d;\n"},
+		{`puts("a\tb")`, "puts(\"a\\tb\");\n"},
+		{"let add = fn(a, b) { a + b };\nadd(1, fn() {})",
+			"let add = fn(a, b) {\n    a + b;\n};\nadd(1, fn() {});\n"},
+		{"if (x > 1) { if (y) { return x; } } else { y }",
+			"if (x > 1) {\n    if (y) {\n        return x;\n    }\n} else {\n    y;\n}\n"},
+		{"if (x) { 1 }; (a + b) * 2; if (x) { 1 }; -2; if (x) { 1 } !y",
+			"if (x) {\n    1;\n};\n(a + b) * 2;\nif (x) {\n    1;\n};\n-2;\nif (x) {\n    1;\n}\n!y;\n"},
+		{"fn(x) { x }(5)", "fn(x) {\n    x;\n}(5);\n"},
+		{"`raw\nstring`", "`raw\nstring`;\n"},
+		{`"\u{65}"; 007`, "\"\\u{65}\";\n007;\n"},
+		{"0xFF+1_000*2.5e3", "0xFF + 1_000 * 2.5e3;\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual := Sprint(parse(t, tc.input))
+			if actual != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSprintNodes(t *testing.T) {
+	program := parse(t, "let f = fn(a) { a * (a + 1) };")
+	let := program.Statements[0].(*ast.LetStatement)
+
+	if s := Sprint(let.Value); s != "fn(a) {\n    a * (a + 1);\n}" {
+		t.Errorf("unexpected expression %q", s)
+	}
+	if s := Sprint(let); s != "let f = fn(a) {\n    a * (a + 1);\n};" {
+		t.Errorf("unexpected statement %q", s)
+	}
+}
+
+// TestRoundTrip checks, for many randomly generated programs, that both the
+// canonical String form and the pretty printed form parse back to the same
+// tree, and that pretty printing is stable.
+func TestRoundTrip(t *testing.T) {
+	g := &generator{rand: rand.New(rand.NewSource(1))}
+
+	for i := 0; i < 2000; i++ {
+		program := g.program()
+		canonical := program.String()
+
+		reparsed := parse(t, canonical)
+		if reparsed.String() != canonical {
+			t.Fatalf("String did not round trip:\n%s\nbecame:\n%s", canonical, reparsed.String())
+		}
+
+		pretty := Sprint(program)
+		reparsed = parse(t, pretty)
+		if reparsed.String() != canonical {
+			t.Fatalf("Sprint did not round trip:\n%s\nparsed as:\n%s\nexpected:\n%s", pretty, reparsed.String(), canonical)
+		}
+		if again := Sprint(reparsed); again != pretty {
+			t.Fatalf("Sprint is not stable:\n%s\nbecame:\n%s", pretty, again)
+		}
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); len(diags) > 0 {
+		t.Fatalf("unexpected parse error in:\n%s\n%s", input, diags[0].Error())
+	}
+	return program
+}
+
+// generator builds random syntax trees.
+type generator struct {
+	rand *rand.Rand
+}
+
+var (
+	genNames   = []string{"a", "b", "foo", "_x", "héllo"}
+	genStrings = []string{"", "hi", "a\nb", `say "hi"`, `back\slash`, "tab\there", "\x01", "日本"}
+	genInfix   = []token.Token{
+		token.New(token.PLUS, "+"), token.New(token.MINUS, "-"),
+		token.New(token.ASTERISK, "*"), token.New(token.SLASH, "/"),
+		token.New(token.LT, "<"), token.New(token.GT, ">"),
+		token.New(token.EQ, "=="), token.New(token.NOT_EQ, "!="),
+	}
+	genPrefix = []string{"!", "-"}
+)
+
+func (g *generator) program() *ast.Program {
+	return &ast.Program{Statements: g.statements(1 + g.rand.Intn(4))}
+}
+
+func (g *generator) statements(n int) []ast.Statement {
+	stmts := make([]ast.Statement, n)
+	for i := range stmts {
+		stmts[i] = g.statement()
+	}
+	return stmts
+}
+
+func (g *generator) statement() ast.Statement {
+	switch g.rand.Intn(4) {
+	case 0:
+		return &ast.LetStatement{Name: g.identifier(), Value: g.expression(3)}
+	case 1:
+		return &ast.ReturnStatement{ReturnValue: g.expression(3)}
+	default:
+		return &ast.ExpressionStatement{Expression: g.expression(3)}
+	}
+}
+
+func (g *generator) block() *ast.BlockStatement {
+	return &ast.BlockStatement{Statements: g.statements(g.rand.Intn(3))}
+}
+
+func (g *generator) identifier() *ast.Identifier {
+	return &ast.Identifier{Value: genNames[g.rand.Intn(len(genNames))]}
+}
+
+func (g *generator) expression(depth int) ast.Expression {
+	if depth <= 0 {
+		return g.atom()
+	}
+	switch g.rand.Intn(8) {
+	case 0:
+		return &ast.PrefixExpression{Operator: genPrefix[g.rand.Intn(len(genPrefix))], Right: g.expression(depth - 1)}
+	case 1, 2:
+		op := genInfix[g.rand.Intn(len(genInfix))]
+		return &ast.InfixExpression{Token: op, Operator: op.Literal(), Left: g.expression(depth - 1), Right: g.expression(depth - 1)}
+	case 3:
+		ie := &ast.IfExpression{Condition: g.expression(depth - 1), Consequence: g.block()}
+		if g.rand.Intn(2) == 0 {
+			ie.Alternative = g.block()
+		}
+		return ie
+	case 4:
+		fl := &ast.FunctionLiteral{Body: g.block()}
+		for _, i := range g.rand.Perm(len(genNames))[:g.rand.Intn(3)] {
+			fl.Parameters = append(fl.Parameters, &ast.Identifier{Value: genNames[i]})
+		}
+		return fl
+	case 5:
+		ce := &ast.CallExpression{Function: g.expression(depth - 1)}
+		for i := g.rand.Intn(3); i > 0; i-- {
+			ce.Arguments = append(ce.Arguments, g.expression(depth-1))
+		}
+		return ce
+	default:
+		return g.atom()
+	}
+}
+
+func (g *generator) atom() ast.Expression {
+	switch g.rand.Intn(5) {
+	case 0:
+		return g.identifier()
+	case 1:
+		return &ast.IntegerLiteral{Value: g.rand.Int63n(1000)}
+	case 2:
+		return &ast.FloatLiteral{Value: math.Ldexp(float64(g.rand.Int63n(1000)), g.rand.Intn(200)-100)}
+	case 3:
+		return &ast.StringLiteral{Value: genStrings[g.rand.Intn(len(genStrings))]}
+	default:
+		return &ast.Boolean{Value: g.rand.Intn(2) == 0}
+	}
+}
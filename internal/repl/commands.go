@@ -0,0 +1,178 @@
+package repl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/code"
+	"github.com/danbrakeley/hai/internal/engine"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+type command struct {
+	name string
+	args string
+	help string
+	run  func(r *repl, arg string)
+}
+
+// commands is populated in init, as :help refers back to it
+var commands []command
+
+func init() {
+	commands = []command{
+		{"eval", "", "evaluate each input and print the result (the default)", modeCommand(modeEval)},
+		{"tokens", "[input]", "show the tokens of input, or of each input from now on", modeCommand(modeTokens)},
+		{"ast", "[input]", "show the syntax tree of input, or of each input from now on", modeCommand(modeAST)},
+		{"bytecode", "[input]", "show the compiled bytecode of input, or of each input from now on", modeCommand(modeBytecode)},
+		{"type", "<input>", "evaluate input and show the type of the result", (*repl).typeCommand},
+		{"env", "", "list the bindings in the environment", (*repl).envCommand},
+		{"reset", "", "forget all bindings and history", (*repl).resetCommand},
+		{"load", "<file>", "run a Hai source file", (*repl).loadCommand},
+		{"save", "<file>", "save the inputs that ran without error to a file", (*repl).saveCommand},
+		{"time", "", "toggle showing how long each evaluation takes", (*repl).timeCommand},
+		{"help", "", "show this help", (*repl).helpCommand},
+	}
+}
+
+func isCommand(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), ":")
+}
+
+// runCommand runs a line of the form ":name arg".
+func (r *repl) runCommand(line string) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(line)[1:], " ")
+	arg = strings.TrimSpace(arg)
+	for _, c := range commands {
+		if c.name == name {
+			c.run(r, arg)
+			return
+		}
+	}
+	fmt.Fprintf(r.out, "unknown command :%s (try :help)\n", name)
+}
+
+// modeCommand returns a command that shows its argument in mode m, or that
+// switches to mode m if there is no argument.
+func modeCommand(m mode) func(r *repl, arg string) {
+	return func(r *repl, arg string) {
+		if arg == "" {
+			r.mode = m
+			return
+		}
+		r.handle(arg, m)
+	}
+}
+
+func (r *repl) typeCommand(arg string) {
+	if arg == "" {
+		fmt.Fprintln(r.out, "usage: :type <input>")
+		return
+	}
+	if result, ok := r.eval(arg); ok && result != nil {
+		fmt.Fprintln(r.out, result.Type())
+	}
+}
+
+func (r *repl) envCommand(string) {
+	eng := r.session.engine
+	for _, name := range eng.Names() {
+		val, _ := eng.Get(name)
+		fmt.Fprintf(r.out, "%s = %s\n", name, r.value(val))
+	}
+}
+
+func (r *repl) resetCommand(string) {
+	r.session.reset()
+}
+
+func (r *repl) loadCommand(arg string) {
+	if arg == "" {
+		fmt.Fprintln(r.out, "usage: :load <file>")
+		return
+	}
+	src, err := os.ReadFile(arg)
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+	r.eval(string(src))
+}
+
+func (r *repl) saveCommand(arg string) {
+	if arg == "" {
+		fmt.Fprintln(r.out, "usage: :save <file>")
+		return
+	}
+	var sb strings.Builder
+	for _, src := range r.session.history {
+		sb.WriteString(src)
+		sb.WriteByte('\n')
+	}
+	if err := os.WriteFile(arg, []byte(sb.String()), 0o644); err != nil {
+		fmt.Fprintln(r.out, err)
+	}
+}
+
+func (r *repl) timeCommand(string) {
+	r.showTime = !r.showTime
+	if r.showTime {
+		fmt.Fprintln(r.out, "timing on")
+	} else {
+		fmt.Fprintln(r.out, "timing off")
+	}
+}
+
+func (r *repl) helpCommand(string) {
+	fmt.Fprintln(r.out, "The last result is bound to _, and each result in turn to _1, _2, ...")
+	fmt.Fprintln(r.out, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(r.out, "  :%-18s %s\n", strings.TrimSpace(c.name+" "+c.args), c.help)
+	}
+}
+
+func (r *repl) printTokens(src string) {
+	l := lexer.New(src, lexer.WithComments())
+	for {
+		tok := l.NextToken()
+		if tok.Is(token.EOF) {
+			break
+		}
+		fmt.Fprintf(r.out, "%-7s %-10s %s\n", tok.Pos(), tok.Type(), tok.Literal())
+	}
+	if diags := l.Diagnostics(); len(diags) > 0 {
+		r.renderer().Render(r.out, src, diags...)
+	}
+}
+
+func (r *repl) printBytecode(src string, program *ast.Program) {
+	comp, ok := r.session.engine.(engine.BytecodeCompiler)
+	if !ok {
+		fmt.Fprintf(r.out, "the %s engine does not use bytecode\n", r.session.engineName)
+		return
+	}
+	bytecode, err := comp.Compile(program)
+	if err != nil {
+		r.printError(src, err)
+		return
+	}
+
+	fmt.Fprint(r.out, bytecode.Instructions)
+	for i, c := range bytecode.Constants {
+		switch c := c.(type) {
+		case *object.CompiledFunction:
+			fmt.Fprintf(r.out, "constant %d: fn, %d params, %d locals\n", i, c.NumParameters, c.NumLocals)
+			for _, line := range strings.SplitAfter(code.Instructions(c.Instructions).String(), "\n") {
+				if line != "" {
+					fmt.Fprint(r.out, "  ", line)
+				}
+			}
+		default:
+			fmt.Fprintf(r.out, "constant %d: %s %s\n", i, c.Type(), c.Inspect())
+		}
+	}
+}
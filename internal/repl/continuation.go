@@ -0,0 +1,79 @@
+package repl
+
+import (
+	"strings"
+
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// continuesExpression holds the tokens that can't end a statement, so input
+// ending in one of them must continue on the next line.
+var continuesExpression = map[token.TokenType]bool{
+	token.ASSIGN:   true,
+	token.PLUS:     true,
+	token.MINUS:    true,
+	token.BANG:     true,
+	token.ASTERISK: true,
+	token.SLASH:    true,
+	token.LT:       true,
+	token.GT:       true,
+	token.EQ:       true,
+	token.NOT_EQ:   true,
+	token.COMMA:    true,
+	token.FUNCTION: true,
+	token.LET:      true,
+	token.IF:       true,
+	token.ELSE:     true,
+	token.RETURN:   true,
+}
+
+// isIncomplete reports if src needs more lines before it can be parsed: it
+// has unclosed parens or braces, ends in an operator, or ends inside a raw
+// string or block comment. Double quoted strings can't span lines, so an
+// unterminated one is left for the parser to report.
+func isIncomplete(src string) bool {
+	l := lexer.New(src, lexer.WithComments())
+
+	depth := 0
+	last := token.EOF
+	for {
+		tok := l.NextToken()
+		if tok.Is(token.EOF) {
+			break
+		}
+
+		switch tok.Type() {
+		case token.LPAREN, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACE:
+			depth--
+			if depth < 0 {
+				// an unmatched closing delimiter can't be fixed by more input
+				return false
+			}
+		case token.COMMENT:
+			continue
+		case token.ILLEGAL:
+			if strings.HasPrefix(tok.Literal(), "`") && hasDiag(l, diag.UnterminatedString) {
+				return true
+			}
+		}
+		last = tok.Type()
+	}
+
+	if hasDiag(l, diag.UnterminatedComment) {
+		return true
+	}
+	return depth > 0 || continuesExpression[last]
+}
+
+func hasDiag(l *lexer.Lexer, code diag.Code) bool {
+	for _, d := range l.Diagnostics() {
+		if d.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package repl
+
+import "testing"
+
+func TestIsIncomplete(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected bool
+	}{
+		{"", false},
+		{"1 + 2", false},
+		{"let add = fn(a, b) {", true},
+		{"let add = fn(a, b) {\n  a + b\n};", false},
+		{"add(1,", true},
+		{"add(1,\n2)", false},
+		{"let x =", true},
+		{"1 +", true},
+		{"1 + // comment", true},
+		{"if (x) { 1 } else", true},
+		{"return", true},
+		{"`raw", true},
+		{"`raw\nstring`", false},
+		{"/* comment", true},
+		{"/* comment */", false},
+		{`"unterminated`, false},
+		{"}", false},
+		{"} {", false},
+		{"let x = @", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			if actual := isIncomplete(tc.input); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/danbrakeley/hai/internal/highlight"
+	"github.com/danbrakeley/hai/internal/lineedit"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/token"
+	"golang.org/x/term"
+)
+
+// lineReader reads a line of input after showing a prompt. It returns io.EOF
+// when there is no more input.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// plainReader reads lines without any editing, for when the input is not a
+// terminal.
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func newPlainReader(in io.Reader, out io.Writer) *plainReader {
+	return &plainReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+func (p *plainReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	if !p.scanner.Scan() {
+		if err := p.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return p.scanner.Text(), nil
+}
+
+// editorReader reads lines with a line editor, and records them in history.
+type editorReader struct {
+	editor *lineedit.Editor
+}
+
+func (r *repl) newEditor(in io.Reader, historyFile string) *editorReader {
+	e := lineedit.New(in, r.out)
+	e.Complete = r.complete
+	if r.color {
+		e.Highlight = highlight.Source
+	}
+	if historyFile != "" {
+		if err := e.LoadHistory(historyFile); err != nil {
+			fmt.Fprintf(r.out, "unable to load history: %s\n", err)
+		}
+	}
+	return &editorReader{editor: e}
+}
+
+func (er *editorReader) ReadLine(prompt string) (string, error) {
+	line, err := er.editor.ReadLine(prompt)
+	if err == nil {
+		// history is a convenience, so failing to save it is not worth
+		// interrupting the session for
+		_ = er.editor.AddHistory(line)
+	}
+	return line, err
+}
+
+// complete finds the completions of the command or identifier before the
+// cursor, from the commands, keywords, builtins, and bound identifiers.
+func (r *repl) complete(before string) (int, []string) {
+	if isCommand(before) && !strings.Contains(before, " ") {
+		word := strings.TrimSpace(before)
+		var matches []string
+		for _, c := range commands {
+			if strings.HasPrefix(":"+c.name, word) {
+				matches = append(matches, ":"+c.name)
+			}
+		}
+		sort.Strings(matches)
+		return len([]rune(word)), matches
+	}
+
+	runes := []rune(before)
+	start := len(runes)
+	for start > 0 && isIdentRune(runes[start-1]) {
+		start--
+	}
+	word := string(runes[start:])
+	if word == "" || unicode.IsDigit(runes[start]) {
+		return 0, nil
+	}
+
+	seen := map[string]bool{}
+	var matches []string
+	add := func(names ...string) {
+		for _, name := range names {
+			if strings.HasPrefix(name, word) && !seen[name] {
+				seen[name] = true
+				matches = append(matches, name)
+			}
+		}
+	}
+	add(token.Keywords()...)
+	for _, b := range object.Builtins {
+		add(b.Name)
+	}
+	add(r.session.engine.Names()...)
+	sort.Strings(matches)
+
+	return len(runes) - start, matches
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func isTerminal(v any) bool {
+	f, ok := v.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
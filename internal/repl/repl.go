@@ -1,30 +1,222 @@
 package repl
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/highlight"
 	"github.com/danbrakeley/hai/internal/lexer"
-	"github.com/danbrakeley/hai/internal/token"
+	"github.com/danbrakeley/hai/internal/lineedit"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/parser"
 )
 
-const PROMPT = ">> "
+const (
+	PROMPT          = ">> "
+	CONTINUE_PROMPT = ".. "
+)
+
+// mode controls what the REPL shows for each input.
+type mode int
+
+const (
+	modeEval mode = iota
+	modeTokens
+	modeAST
+	modeBytecode
+)
+
+type repl struct {
+	out      io.Writer
+	session  *session
+	mode     mode
+	showTime bool
+	color    bool
+}
+
+type Option func(*config)
+
+type config struct {
+	engine      string
+	historyFile string
+	color       *bool
+}
+
+// WithEngine sets the engine that inputs are run on (see engine.Names). The
+// default is the vm.
+func WithEngine(name string) Option {
+	return func(c *config) {
+		c.engine = name
+	}
+}
+
+// WithHistoryFile sets the file that input history is kept in, when using
+// the line editor. The default is ~/.hai_history, and "" disables it.
+func WithHistoryFile(path string) Option {
+	return func(c *config) {
+		c.historyFile = path
+	}
+}
 
-func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+// WithColor turns syntax highlighting of input, results and errors on or
+// off. By default, it is on if out is a terminal and NO_COLOR is not set.
+func WithColor(enabled bool) Option {
+	return func(c *config) {
+		c.color = &enabled
+	}
+}
+
+// Start reads inputs from in and writes the results to out, until in runs out.
+// If both in and out are terminals, inputs are read with a line editor.
+func Start(in io.Reader, out io.Writer, opts ...Option) error {
+	cfg := config{engine: "vm"}
+	if home, err := os.UserHomeDir(); err == nil {
+		cfg.historyFile = filepath.Join(home, ".hai_history")
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s, err := newSession(cfg.engine, out)
+	if err != nil {
+		return err
+	}
+
+	r := &repl{out: out, session: s, color: highlight.Enabled(out)}
+	if cfg.color != nil {
+		r.color = *cfg.color
+	}
+	var lines lineReader
+	if isTerminal(in) && isTerminal(out) {
+		lines = r.newEditor(in, cfg.historyFile)
+	} else {
+		lines = newPlainReader(in, out)
+	}
+
+	var buf strings.Builder
 	for {
-		fmt.Fprint(out, PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
-			return
+		prompt := PROMPT
+		if buf.Len() > 0 {
+			prompt = CONTINUE_PROMPT
+		}
+		line, err := lines.ReadLine(prompt)
+		if errors.Is(err, lineedit.ErrInterrupted) {
+			// abandon the input
+			buf.Reset()
+			continue
+		}
+		scanned := err == nil
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if !scanned && buf.Len() == 0 {
+			return nil
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
+		if scanned {
+			if buf.Len() == 0 && isCommand(line) {
+				r.runCommand(line)
+				continue
+			}
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString(line)
+			if isIncomplete(buf.String()) {
+				continue
+			}
+		}
+
+		src := buf.String()
+		buf.Reset()
+		r.handle(src, r.mode)
+
+		if !scanned {
+			// the input ended part way through a statement
+			return nil
+		}
+	}
+}
 
-		for tok := l.NextToken(); tok.Type() != token.EOF; tok = l.NextToken() {
-			fmt.Fprintf(out, "%+v\n", tok)
+// handle shows src in the given mode.
+func (r *repl) handle(src string, m mode) {
+	if strings.TrimSpace(src) == "" {
+		return
+	}
+	switch m {
+	case modeTokens:
+		r.printTokens(src)
+	case modeAST:
+		if program, ok := r.parse(src); ok {
+			ast.Fprint(r.out, program)
+		}
+	case modeBytecode:
+		if program, ok := r.parse(src); ok {
+			r.printBytecode(src, program)
 		}
+	default:
+		if result, ok := r.eval(src); ok && result != nil {
+			fmt.Fprintln(r.out, r.value(result))
+		}
+	}
+}
+
+// parse parses src, printing any problems found.
+func (r *repl) parse(src string) (*ast.Program, bool) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); len(diags) > 0 {
+		r.renderer().Render(r.out, src, diags...)
+		return nil, false
+	}
+	return program, true
+}
+
+// eval runs src, printing any problems found. It returns the result of the
+// last statement, which may be nil.
+func (r *repl) eval(src string) (object.Object, bool) {
+	program, ok := r.parse(src)
+	if !ok {
+		return nil, false
+	}
+
+	start := time.Now()
+	result, err := r.session.run(program, src)
+	if r.showTime {
+		fmt.Fprintf(r.out, "time: %s\n", time.Since(start))
+	}
+
+	if err != nil {
+		r.printError(src, err)
+		return nil, false
+	}
+	return result, true
+}
+
+func (r *repl) printError(src string, err error) {
+	if d, ok := err.(diag.Diagnostic); ok {
+		r.renderer().Render(r.out, src, d)
+	} else if r.color {
+		fmt.Fprintln(r.out, highlight.Wrap(highlight.Illegal, "ERROR: "+err.Error()))
+	} else {
+		fmt.Fprintf(r.out, "ERROR: %s\n", err)
+	}
+}
+
+func (r *repl) renderer() diag.Renderer {
+	return highlight.Renderer(r.color)
+}
+
+// value returns the form of obj shown to the user.
+func (r *repl) value(obj object.Object) string {
+	if r.color {
+		return highlight.Value(obj)
 	}
+	return obj.Inspect()
 }
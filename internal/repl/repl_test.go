@@ -0,0 +1,204 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/compiler"
+	"github.com/danbrakeley/hai/internal/highlight"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/parser"
+)
+
+func TestStartMultiline(t *testing.T) {
+	input := "let add = fn(a, b) {\n  a + b\n};\nadd(1,\n2)\n`a\nb`\n"
+
+	var out strings.Builder
+	Start(strings.NewReader(input), &out)
+
+	expected := ">> .. .. >> .. 3\n>> .. a\nb\n>> "
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestStartPuts(t *testing.T) {
+	var out strings.Builder
+	Start(strings.NewReader("puts(\"hi\")\n"), &out)
+
+	expected := ">> hi\nnull\n>> "
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestStartUnfinishedInput(t *testing.T) {
+	var out strings.Builder
+	Start(strings.NewReader("1 +\n"), &out)
+
+	if !strings.Contains(out.String(), "error[E0202]") {
+		t.Errorf("expected a missing expression error, got %q", out.String())
+	}
+}
+
+func TestCommands(t *testing.T) {
+	saved := filepath.Join(t.TempDir(), "session.hai")
+
+	cases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"tokens", ":tokens let x", []string{"1:1     let        let\n", "1:5     ident      x\n"}},
+		{"ast", ":ast 1 + 2", []string{"InfixExpression + (1:3)\n", "left: IntegerLiteral 1 (1:1)\n"}},
+		{"bytecode", ":bytecode 1 + 2", []string{"0006 OpAdd\n", "constant 1: integer 2\n"}},
+		{"mode", ":ast\n1\n:eval\n2", []string{"IntegerLiteral 1 (1:1)\n", ">> 2\n"}},
+		{"type", `:type "a"`, []string{"string\n"}},
+		{"env", "let a = 1;\nlet b = true;\n:env", []string{"a = 1\nb = true\n"}},
+		{"reset", "let a = 1;\n:reset\na", []string{"identifier not found: a"}},
+		{"time", ":time\n1", []string{"timing on\n", "time: "}},
+		{"help", ":help", []string{":load <file>"}},
+		{"unknown", ":nope", []string{"unknown command :nope"}},
+		{"save and load", "let a = 1;\na + b\nlet b = a + 1;\n:save " + saved + "\n:reset\n:load " + saved + "\nb", []string{">> 2\n"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out strings.Builder
+			Start(strings.NewReader(tc.input+"\n"), &out)
+			for _, e := range tc.expected {
+				if !strings.Contains(out.String(), e) {
+					t.Errorf("expected output to contain %q, got:\n%s", e, out.String())
+				}
+			}
+		})
+	}
+
+	src, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(src) != "let a = 1;\nlet b = a + 1;\n" {
+		t.Errorf("unexpected saved session %q", src)
+	}
+}
+
+func TestSession(t *testing.T) {
+	input := `let x = 5;
+x * 2
+_ + 1
+if (false) { "null results are not numbered" }
+_1 + _2
+let f = fn(a) { a + x };
+f(_)
+:bytecode x
+:env
+`
+	for _, eng := range []string{"vm", "eval"} {
+		t.Run(eng, func(t *testing.T) {
+			var out strings.Builder
+			if err := Start(strings.NewReader(input), &out, WithEngine(eng)); err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range []string{">> 10\n", ">> 11\n", ">> 21\n", ">> 26\n", "_ = 26\n_1 = 10\n_2 = 11\n_3 = 21\n_4 = 26\n"} {
+				if !strings.Contains(out.String(), e) {
+					t.Errorf("expected output to contain %q, got:\n%s", e, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestSessionErrors(t *testing.T) {
+	// a failed definition can't be used later, on either engine
+	for _, eng := range []string{"vm", "eval"} {
+		t.Run(eng, func(t *testing.T) {
+			var out strings.Builder
+			Start(strings.NewReader("let a = 1 / 0;\na\n"), &out, WithEngine(eng))
+			if strings.Count(out.String(), "ERROR")+strings.Count(out.String(), "error[") != 2 {
+				t.Errorf("expected 2 errors, got:\n%s", out.String())
+			}
+		})
+	}
+
+	if err := Start(strings.NewReader(""), io.Discard, WithEngine("jit")); err == nil {
+		t.Errorf("expected an error for an unknown engine")
+	}
+}
+
+func TestSessionGlobalsFull(t *testing.T) {
+	s, err := newSession("vm", io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < compiler.MaxGlobals-1; i++ {
+		s.engine.Define(fmt.Sprintf("g%d", i), &object.Integer{Value: int64(i)})
+	}
+
+	// _ takes the last slot, so results are no longer numbered
+	for range 2 {
+		if _, err := s.run(parser.New(lexer.New("1")).ParseProgram(), "1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.numResults != 0 {
+		t.Errorf("expected no numbered results, got %d", s.numResults)
+	}
+	if val, ok := s.engine.Get("_"); !ok || val.Inspect() != "1" {
+		t.Errorf("expected _ = 1, got %v", val)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s, _ := newSession("vm", io.Discard)
+	r := &repl{out: io.Discard, session: s}
+	r.eval("let length = 1; let lettuce = 2;")
+
+	cases := []struct {
+		before   string
+		wordLen  int
+		expected string
+	}{
+		{"le", 2, "len,length,let,lettuce"},
+		{"x + let", 3, "let,lettuce"},
+		{"re", 2, "return"},
+		{"pu", 2, "puts"},
+		{":t", 2, ":time,:tokens,:type"},
+		{":type le", 2, "len,length,let,lettuce"},
+		{"1", 0, ""},
+		{"x + ", 0, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.before, func(t *testing.T) {
+			wordLen, candidates := r.complete(tc.before)
+			if wordLen != tc.wordLen && len(candidates) > 0 {
+				t.Errorf("expected word length %d, got %d", tc.wordLen, wordLen)
+			}
+			if actual := strings.Join(candidates, ","); actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestColor(t *testing.T) {
+	var out strings.Builder
+	Start(strings.NewReader("\"a\"\nlet = 1;\n"), &out, WithColor(true))
+	for _, e := range []string{highlight.Green + "a" + highlight.Reset, highlight.Magenta + "let" + highlight.Reset} {
+		if !strings.Contains(out.String(), e) {
+			t.Errorf("expected output to contain %q, got %q", e, out.String())
+		}
+	}
+
+	out.Reset()
+	Start(strings.NewReader("\"a\"\nlet = 1;\n"), &out, WithColor(false))
+	if strings.Contains(out.String(), "\x1b[") {
+		t.Errorf("expected no color, got %q", out.String())
+	}
+}
@@ -0,0 +1,56 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/engine"
+	"github.com/danbrakeley/hai/internal/object"
+)
+
+// session is the state that carries over from one input to the next: the
+// globals of the engine (an environment for the evaluator, or a symbol
+// table, constant pool and globals store for the vm), and the history of
+// inputs and results.
+type session struct {
+	engineName string
+	out        io.Writer // where programs write to with puts
+	engine     engine.Engine
+	numResults int      // results so far, which are bound to _1, _2, ...
+	history    []string // inputs that ran without error, for :save
+}
+
+func newSession(engineName string, out io.Writer) (*session, error) {
+	eng, err := engine.New(engineName, engine.WithOutput(out))
+	if err != nil {
+		return nil, err
+	}
+	return &session{engineName: engineName, out: out, engine: eng}, nil
+}
+
+// run runs program, which was parsed from src. A result other than null is
+// bound to _, and to the next of _1, _2, ... until there is no room for more
+// globals.
+func (s *session) run(program *ast.Program, src string) (object.Object, error) {
+	result, err := s.engine.Run(program)
+	if err != nil {
+		return nil, err
+	}
+	s.history = append(s.history, src)
+
+	if result != nil && result.Type() != object.NULL {
+		next := fmt.Sprintf("_%d", s.numResults+1)
+		if s.engine.Define("_", result) == nil && s.engine.Define(next, result) == nil {
+			s.numResults++
+		}
+	}
+	return result, nil
+}
+
+// reset forgets all bindings and history.
+func (s *session) reset() {
+	s.engine, _ = engine.New(s.engineName, engine.WithOutput(s.out))
+	s.numResults = 0
+	s.history = nil
+}
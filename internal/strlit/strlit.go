@@ -0,0 +1,145 @@
+// Package strlit reads and writes Hai string literals. It is shared by the
+// lexer, and by the packages that print syntax trees, so that they need not
+// depend on the lexer.
+package strlit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Quote returns a double quoted string literal that Unquote turns back into
+// s. Control characters are written as escape sequences.
+func Quote(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(&sb, `\u{%X}`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// Unquote returns the value of a STRING token's literal, with the quotes
+// removed and any escape sequences decoded.
+func Unquote(lit string) (string, error) {
+	if len(lit) < 2 {
+		return "", errors.New("string literal is missing quotes")
+	}
+
+	quote := lit[0]
+	if lit[len(lit)-1] != quote || (quote != '"' && quote != '`') {
+		return "", errors.New("string literal is missing quotes")
+	}
+
+	body := lit[1 : len(lit)-1]
+	if quote == '`' || strings.IndexByte(body, '\\') < 0 {
+		return body, nil
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(body))
+	for i := 0; i < len(body); {
+		if body[i] != '\\' {
+			sb.WriteByte(body[i])
+			i++
+			continue
+		}
+		r, n, err := DecodeEscape(body[i:])
+		if err != nil {
+			return "", err
+		}
+		sb.WriteRune(r)
+		i += n
+	}
+	return sb.String(), nil
+}
+
+// DecodeEscape decodes the escape sequence at the start of s, which must
+// start with a backslash. Returns the decoded rune and the length of the
+// escape sequence. On error, the length is of the invalid part.
+func DecodeEscape(s string) (rune, int, error) {
+	if len(s) < 2 {
+		return 0, 1, errors.New("incomplete escape sequence")
+	}
+
+	switch s[1] {
+	case 'n':
+		return '\n', 2, nil
+	case 't':
+		return '\t', 2, nil
+	case 'r':
+		return '\r', 2, nil
+	case '"':
+		return '"', 2, nil
+	case '\\':
+		return '\\', 2, nil
+	case 'u':
+		return decodeUnicodeEscape(s)
+	}
+
+	r, size := utf8.DecodeRuneInString(s[1:])
+	return 0, 1 + size, fmt.Errorf("unknown escape sequence '\\%c'", r)
+}
+
+// decodeUnicodeEscape decodes an escape of the form \u{XXXX}, where there are
+// between 1 and 6 hex digits
+func decodeUnicodeEscape(s string) (rune, int, error) {
+	if len(s) < 3 || s[2] != '{' {
+		return 0, 2, errors.New("unicode escape must look like \\u{XXXX}")
+	}
+
+	var r rune
+	i := 3
+	for ; i < len(s) && s[i] != '}'; i++ {
+		d, ok := hexValue(s[i])
+		if !ok || i-3 >= 6 {
+			return 0, i, errors.New("unicode escape must have between 1 and 6 hex digits")
+		}
+		r = r*16 + d
+	}
+	if i >= len(s) {
+		return 0, i, errors.New("unicode escape is missing closing '}'")
+	}
+	if i == 3 {
+		return 0, i + 1, errors.New("unicode escape must have between 1 and 6 hex digits")
+	}
+	if !utf8.ValidRune(r) {
+		return 0, i + 1, fmt.Errorf("invalid unicode code point U+%X", r)
+	}
+
+	return r, i + 1, nil
+}
+
+func hexValue(ch byte) (rune, bool) {
+	switch {
+	case '0' <= ch && ch <= '9':
+		return rune(ch - '0'), true
+	case 'a' <= ch && ch <= 'f':
+		return rune(ch-'a') + 10, true
+	case 'A' <= ch && ch <= 'F':
+		return rune(ch-'A') + 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,30 @@
+package strlit
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected string
+	}{
+		{"", `""`},
+		{"hello", `"hello"`},
+		{"a\nb\tc\rd", `"a\nb\tc\rd"`},
+		{`say "hi" \o/`, `"say \"hi\" \\o/"`},
+		{"bell\a", `"bell\u{7}"`},
+		{"héllo \U0001F600", "\"héllo \U0001F600\""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			actual := Quote(tc.value)
+			if actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+			value, err := Unquote(actual)
+			if err != nil || value != tc.value {
+				t.Errorf("expected to unquote to %q, got %q (%v)", tc.value, value, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package syntaxjson
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/strlit"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// operators maps the value of prefix and infix expressions to the type of
+// their token.
+var operators = map[string]token.TokenType{
+	"+":  token.PLUS,
+	"-":  token.MINUS,
+	"!":  token.BANG,
+	"*":  token.ASTERISK,
+	"/":  token.SLASH,
+	"<":  token.LT,
+	">":  token.GT,
+	"==": token.EQ,
+	"!=": token.NOT_EQ,
+}
+
+// AST turns n back into the ast.Node it was made from. Tokens that are not
+// at the start or end of a node, such as the operator of an infix
+// expression, have no span.
+func (n *Node) AST() (ast.Node, error) {
+	switch n.Kind {
+	case "Program":
+		stmts, err := n.statements()
+		return &ast.Program{Statements: stmts}, err
+	case "BlockStatement":
+		return n.block()
+	case "LetStatement":
+		name, err := n.identifier("name")
+		if err != nil {
+			return nil, err
+		}
+		value, err := n.expression("value")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.LetStatement{Token: n.keyword(token.LET, "let"), Name: name, Value: value}, nil
+	case "ReturnStatement":
+		value, err := n.expression("value")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ReturnStatement{Token: n.keyword(token.RETURN, "return"), ReturnValue: value}, nil
+	case "ExpressionStatement":
+		expr, err := n.expression("expression")
+		if err != nil {
+			return nil, err
+		}
+		tok := firstToken(expr)
+		if tok.Pos() != n.Span.Start {
+			// the expression is in parentheses
+			tok = n.keyword(token.LPAREN, "(")
+		}
+		return &ast.ExpressionStatement{Token: tok, Expression: expr}, nil
+	case "BadStatement":
+		return &ast.BadStatement{Token: n.leaf(token.ILLEGAL, ""), End: n.Span.End}, nil
+	}
+
+	return n.expressionNode()
+}
+
+func (n *Node) expressionNode() (ast.Expression, error) {
+	switch n.Kind {
+	case "Identifier":
+		return &ast.Identifier{Token: n.leaf(token.IDENT, n.Value), Value: n.Value}, nil
+	case "IntegerLiteral":
+		lit := &ast.IntegerLiteral{Token: n.leaf(token.INT, n.Value)}
+		v, err := strconv.ParseInt(n.Value, 10, 64)
+		if errors.Is(err, strconv.ErrRange) {
+			var ok bool
+			if lit.Big, ok = new(big.Int).SetString(n.Value, 10); ok {
+				return lit, nil
+			}
+		}
+		if err != nil {
+			return nil, n.errorf("invalid integer %q", n.Value)
+		}
+		lit.Value = v
+		return lit, nil
+	case "FloatLiteral":
+		v, err := strconv.ParseFloat(n.Value, 64)
+		if err != nil {
+			return nil, n.errorf("invalid float %q", n.Value)
+		}
+		return &ast.FloatLiteral{Token: n.leaf(token.FLOAT, n.Value), Value: v}, nil
+	case "StringLiteral":
+		return &ast.StringLiteral{Token: n.leaf(token.STRING, strlit.Quote(n.Value)), Value: n.Value}, nil
+	case "Boolean":
+		switch n.Value {
+		case "true":
+			return &ast.Boolean{Token: n.leaf(token.TRUE, n.Value), Value: true}, nil
+		case "false":
+			return &ast.Boolean{Token: n.leaf(token.FALSE, n.Value), Value: false}, nil
+		}
+		return nil, n.errorf("invalid boolean %q", n.Value)
+	case "PrefixExpression":
+		typ, ok := operators[n.Value]
+		if !ok || (typ != token.BANG && typ != token.MINUS) {
+			return nil, n.errorf("invalid prefix operator %q", n.Value)
+		}
+		right, err := n.expression("right")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.PrefixExpression{Token: n.keyword(typ, n.Value), Operator: n.Value, Right: right}, nil
+	case "InfixExpression":
+		typ, ok := operators[n.Value]
+		if !ok || typ == token.BANG {
+			return nil, n.errorf("invalid infix operator %q", n.Value)
+		}
+		left, err := n.expression("left")
+		if err != nil {
+			return nil, err
+		}
+		right, err := n.expression("right")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.InfixExpression{Token: token.New(typ, n.Value), Left: left, Operator: n.Value, Right: right}, nil
+	case "IfExpression":
+		ie := &ast.IfExpression{Token: n.keyword(token.IF, "if")}
+		var err error
+		if ie.Condition, err = n.expression("condition"); err != nil {
+			return nil, err
+		}
+		c, err := n.child("consequence")
+		if err != nil {
+			return nil, err
+		}
+		if ie.Consequence, err = c.block(); err != nil {
+			return nil, err
+		}
+		if a := n.optionalChild("alternative"); a != nil {
+			if ie.Alternative, err = a.block(); err != nil {
+				return nil, err
+			}
+		}
+		return ie, nil
+	case "FunctionLiteral":
+		fl := &ast.FunctionLiteral{Token: n.keyword(token.FUNCTION, "fn"), Parameters: []*ast.Identifier{}}
+		for _, c := range n.Children {
+			if c.Field != "parameter" {
+				continue
+			}
+			param, err := c.expressionNode()
+			if err != nil {
+				return nil, err
+			}
+			ident, ok := param.(*ast.Identifier)
+			if !ok {
+				return nil, c.errorf("parameter is a %s, not an Identifier", c.Kind)
+			}
+			fl.Parameters = append(fl.Parameters, ident)
+		}
+		body, err := n.child("body")
+		if err != nil {
+			return nil, err
+		}
+		if fl.Body, err = body.block(); err != nil {
+			return nil, err
+		}
+		return fl, nil
+	case "BadExpression":
+		return &ast.BadExpression{Token: n.leaf(token.ILLEGAL, ""), End: n.Span.End}, nil
+	case "CallExpression":
+		function, err := n.expression("function")
+		if err != nil {
+			return nil, err
+		}
+		ce := &ast.CallExpression{
+			Token:     token.New(token.LPAREN, "("),
+			Function:  function,
+			Arguments: []ast.Expression{},
+			Rparen:    n.closing(token.RPAREN, ")"),
+		}
+		for _, c := range n.Children {
+			if c.Field != "argument" {
+				continue
+			}
+			arg, err := c.expressionNode()
+			if err != nil {
+				return nil, err
+			}
+			ce.Arguments = append(ce.Arguments, arg)
+		}
+		return ce, nil
+	}
+
+	return nil, n.errorf("unknown kind of node %q", n.Kind)
+}
+
+func (n *Node) block() (*ast.BlockStatement, error) {
+	if n.Kind != "BlockStatement" {
+		return nil, n.errorf("expected a BlockStatement, got %s", n.Kind)
+	}
+	stmts, err := n.statements()
+	if err != nil {
+		return nil, err
+	}
+	return &ast.BlockStatement{
+		Token:      n.keyword(token.LBRACE, "{"),
+		Statements: stmts,
+		Rbrace:     n.closing(token.RBRACE, "}"),
+	}, nil
+}
+
+func (n *Node) statements() ([]ast.Statement, error) {
+	stmts := []ast.Statement{}
+	for _, c := range n.Children {
+		if c.Field != "statement" {
+			continue
+		}
+		node, err := c.AST()
+		if err != nil {
+			return nil, err
+		}
+		stmt, ok := node.(ast.Statement)
+		if !ok {
+			return nil, c.errorf("%s is not a statement", c.Kind)
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
+func (n *Node) identifier(field string) (*ast.Identifier, error) {
+	expr, err := n.expression(field)
+	if err != nil {
+		return nil, err
+	}
+	ident, ok := expr.(*ast.Identifier)
+	if !ok {
+		return nil, n.errorf("%s is not an Identifier", field)
+	}
+	return ident, nil
+}
+
+func (n *Node) expression(field string) (ast.Expression, error) {
+	c, err := n.child(field)
+	if err != nil {
+		return nil, err
+	}
+	return c.expressionNode()
+}
+
+func (n *Node) child(field string) (*Node, error) {
+	if c := n.optionalChild(field); c != nil {
+		return c, nil
+	}
+	return nil, n.errorf("missing %s", field)
+}
+
+func (n *Node) optionalChild(field string) *Node {
+	for _, c := range n.Children {
+		if c.Field == field {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *Node) errorf(format string, a ...any) error {
+	return fmt.Errorf("%s at %s: %s", n.Kind, n.Span, fmt.Sprintf(format, a...))
+}
+
+// leaf returns the token of a node that is made of a single token.
+func (n *Node) leaf(typ token.TokenType, lit string) token.Token {
+	return token.New(typ, lit).WithSpan(n.Span)
+}
+
+// keyword returns the token that starts n.
+func (n *Node) keyword(typ token.TokenType, lit string) token.Token {
+	start := n.Span.Start
+	if !start.IsValid() {
+		return token.New(typ, lit)
+	}
+	end := token.Pos{Offset: start.Offset + len(lit), Line: start.Line, Column: start.Column + utf8.RuneCountInString(lit)}
+	return token.New(typ, lit).WithSpan(token.Span{File: n.Span.File, Start: start, End: end})
+}
+
+// closing returns the single character token that ends n.
+func (n *Node) closing(typ token.TokenType, lit string) token.Token {
+	end := n.Span.End
+	if !end.IsValid() {
+		return token.New(typ, lit)
+	}
+	start := token.Pos{Offset: end.Offset - 1, Line: end.Line, Column: end.Column - 1}
+	return token.New(typ, lit).WithSpan(token.Span{File: n.Span.File, Start: start, End: end})
+}
+
+// firstToken returns the token that expr starts with.
+func firstToken(expr ast.Expression) token.Token {
+	switch e := expr.(type) {
+	case *ast.InfixExpression:
+		return firstToken(e.Left)
+	case *ast.CallExpression:
+		return firstToken(e.Function)
+	case *ast.Identifier:
+		return e.Token
+	case *ast.IntegerLiteral:
+		return e.Token
+	case *ast.FloatLiteral:
+		return e.Token
+	case *ast.StringLiteral:
+		return e.Token
+	case *ast.Boolean:
+		return e.Token
+	case *ast.PrefixExpression:
+		return e.Token
+	case *ast.IfExpression:
+		return e.Token
+	case *ast.FunctionLiteral:
+		return e.Token
+	default:
+		return token.Token{}
+	}
+}
@@ -0,0 +1,258 @@
+// Package syntaxjson converts tokens and syntax trees to and from JSON, so
+// that tools written in other languages can use the results of lexing and
+// parsing Hai source. This is what `hai tokens --json` and `hai ast --json`
+// write.
+//
+// # Schema
+//
+// Both documents are objects with a "version" (currently 1, see Version) and a
+// list of "diagnostics" describing any problems found:
+//
+//	{"version": 1, "tokens": [token...], "diagnostics": [diagnostic...]}
+//	{"version": 1, "root": node, "diagnostics": [diagnostic...]}
+//
+// A span gives a half-open region of source. Offsets are in bytes, starting
+// at 0, and lines and columns start at 1, with columns counted in runes. The
+// file is left out if it is not known.
+//
+//	{"file": "a.hai", "start": {"offset": 0, "line": 1, "column": 1}, "end": {...}}
+//
+// A token has a type, which is the name of the token.TokenType constant in
+// snake case (ie "ident", "not_eq" or "comment"), the literal text it was read
+// from, and a span. Comments are included, and the final "eof" token is not.
+//
+//	{"type": "ident", "literal": "x", "span": span}
+//
+// A node has a kind, which is the name of the ast type (ie "LetStatement" or
+// "InfixExpression"), the field of its parent that holds it, its span, and
+// its children in source order. Leaf nodes and operators also have a value.
+//
+//	{"kind": "InfixExpression", "field": "value", "span": span, "value": "+", "children": [node...]}
+//
+// The kinds, with the fields of their children and what their value holds:
+//
+//	Program              statement...
+//	LetStatement         name, value
+//	ReturnStatement      value
+//	ExpressionStatement  expression
+//	BlockStatement       statement...
+//	Identifier           value: the name
+//	IntegerLiteral       value: the integer, in decimal
+//	FloatLiteral         value: the float, in its shortest exact form
+//	StringLiteral        value: the string, after escapes are replaced
+//	Boolean              value: "true" or "false"
+//	PrefixExpression     right; value: the operator
+//	InfixExpression      left, right; value: the operator
+//	IfExpression         condition, consequence, alternative (if there is an else)
+//	FunctionLiteral      parameter..., body
+//	CallExpression       function, argument...
+//	BadStatement         (a statement that could not be parsed)
+//	BadExpression        (a let or return value that could not be parsed)
+//
+// A diagnostic has a severity ("error", "warning" or "note"), a code (ie
+// "E0201"), a message and a span, and may also have a hint. If the problem is
+// an unexpected token, the token types that were expected and the one that
+// was found are given as "expected" and "actual".
+//
+// New kinds and fields may be added without changing the version, so readers
+// should ignore what they do not know.
+package syntaxjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/diag"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/numlit"
+	"github.com/danbrakeley/hai/internal/parser"
+	"github.com/danbrakeley/hai/internal/token"
+)
+
+// Version is the version of the schema, which changes only when a reader of
+// the old schema could be broken by the new one.
+const Version = 1
+
+// Tokens is the document written by `hai tokens --json`.
+type Tokens struct {
+	Version     int               `json:"version"`
+	Tokens      []token.Token     `json:"tokens"`
+	Diagnostics []diag.Diagnostic `json:"diagnostics"`
+}
+
+// Tree is the document written by `hai ast --json`.
+type Tree struct {
+	Version     int               `json:"version"`
+	Root        *Node             `json:"root"`
+	Diagnostics []diag.Diagnostic `json:"diagnostics"`
+}
+
+// Node is the JSON form of an ast.Node.
+type Node struct {
+	Kind     string     `json:"kind"`
+	Field    string     `json:"field,omitempty"`
+	Span     token.Span `json:"span"`
+	Value    string     `json:"value,omitempty"`
+	Children []*Node    `json:"children,omitempty"`
+}
+
+// Lex reads all the tokens in src, including comments.
+func Lex(filename, src string) *Tokens {
+	l := lexer.New(src, lexer.WithFilename(filename), lexer.WithComments())
+	doc := &Tokens{Version: Version, Tokens: []token.Token{}}
+	for {
+		tok := l.NextToken()
+		if tok.Is(token.EOF) {
+			break
+		}
+		doc.Tokens = append(doc.Tokens, tok)
+	}
+	doc.Diagnostics = append([]diag.Diagnostic{}, l.Diagnostics()...)
+	return doc
+}
+
+// Parse parses src. If there are problems, the tree holds whatever could be
+// parsed, with BadStatement and BadExpression nodes standing in for the rest.
+func Parse(filename, src string) *Tree {
+	p := parser.New(lexer.New(src, lexer.WithFilename(filename)))
+	program := p.ParseProgram()
+	return &Tree{
+		Version:     Version,
+		Root:        FromAST(program),
+		Diagnostics: append([]diag.Diagnostic{}, p.Diagnostics()...),
+	}
+}
+
+// DecodeTokens reads a Tokens document from r.
+func DecodeTokens(r io.Reader) (*Tokens, error) {
+	var doc Tokens
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(doc.Version); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// DecodeTree reads a Tree document from r. Use Node.AST to turn its root back
+// into an ast.Node.
+func DecodeTree(r io.Reader) (*Tree, error) {
+	var doc Tree
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(doc.Version); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func checkVersion(v int) error {
+	if v != Version {
+		return fmt.Errorf("unsupported schema version %d (want %d)", v, Version)
+	}
+	return nil
+}
+
+// FromAST returns the JSON form of the tree rooted at node. Parts of the tree
+// that are missing, because they failed to parse, are left out.
+func FromAST(node ast.Node) *Node {
+	return fromAST("", node)
+}
+
+func fromAST(field string, node ast.Node) *Node {
+	if isNil(node) {
+		return nil
+	}
+	n := &Node{Field: field, Span: ast.Span(node)}
+	add := func(field string, child ast.Node) {
+		if c := fromAST(field, child); c != nil {
+			n.Children = append(n.Children, c)
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.Program:
+		n.Kind = "Program"
+		for _, s := range node.Statements {
+			add("statement", s)
+		}
+	case *ast.LetStatement:
+		n.Kind = "LetStatement"
+		add("name", node.Name)
+		add("value", node.Value)
+	case *ast.ReturnStatement:
+		n.Kind = "ReturnStatement"
+		add("value", node.ReturnValue)
+	case *ast.ExpressionStatement:
+		n.Kind = "ExpressionStatement"
+		add("expression", node.Expression)
+	case *ast.BlockStatement:
+		n.Kind = "BlockStatement"
+		for _, s := range node.Statements {
+			add("statement", s)
+		}
+	case *ast.Identifier:
+		n.Kind = "Identifier"
+		n.Value = node.Value
+	case *ast.IntegerLiteral:
+		n.Kind = "IntegerLiteral"
+		n.Value = node.String()
+	case *ast.FloatLiteral:
+		n.Kind = "FloatLiteral"
+		n.Value = numlit.FormatFloat(node.Value)
+	case *ast.StringLiteral:
+		n.Kind = "StringLiteral"
+		n.Value = node.Value
+	case *ast.Boolean:
+		n.Kind = "Boolean"
+		n.Value = strconv.FormatBool(node.Value)
+	case *ast.PrefixExpression:
+		n.Kind = "PrefixExpression"
+		n.Value = node.Operator
+		add("right", node.Right)
+	case *ast.InfixExpression:
+		n.Kind = "InfixExpression"
+		n.Value = node.Operator
+		add("left", node.Left)
+		add("right", node.Right)
+	case *ast.IfExpression:
+		n.Kind = "IfExpression"
+		add("condition", node.Condition)
+		add("consequence", node.Consequence)
+		add("alternative", node.Alternative)
+	case *ast.FunctionLiteral:
+		n.Kind = "FunctionLiteral"
+		for _, param := range node.Parameters {
+			add("parameter", param)
+		}
+		add("body", node.Body)
+	case *ast.CallExpression:
+		n.Kind = "CallExpression"
+		add("function", node.Function)
+		for _, arg := range node.Arguments {
+			add("argument", arg)
+		}
+	case *ast.BadStatement:
+		n.Kind = "BadStatement"
+	case *ast.BadExpression:
+		n.Kind = "BadExpression"
+	default:
+		n.Kind = fmt.Sprintf("%T", node)
+	}
+	return n
+}
+
+// isNil reports if node is nil, including a nil pointer of a node type.
+func isNil(node ast.Node) bool {
+	if node == nil {
+		return true
+	}
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
@@ -0,0 +1,136 @@
+package syntaxjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+	doc := Lex("a.hai", "x != 1 // hi")
+
+	expected := `{"version":1,"tokens":[` +
+		`{"type":"ident","literal":"x","span":{"file":"a.hai","start":{"offset":0,"line":1,"column":1},"end":{"offset":1,"line":1,"column":2}}},` +
+		`{"type":"not_eq","literal":"!=","span":{"file":"a.hai","start":{"offset":2,"line":1,"column":3},"end":{"offset":4,"line":1,"column":5}}},` +
+		`{"type":"int","literal":"1","span":{"file":"a.hai","start":{"offset":5,"line":1,"column":6},"end":{"offset":6,"line":1,"column":7}}},` +
+		`{"type":"comment","literal":"// hi","span":{"file":"a.hai","start":{"offset":7,"line":1,"column":8},"end":{"offset":12,"line":1,"column":13}}}` +
+		`],"diagnostics":[]}`
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+
+	decoded, err := DecodeTokens(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, doc) {
+		t.Errorf("decoded tokens differ:\n%+v\n%+v", decoded, doc)
+	}
+}
+
+func TestTokensDiagnostics(t *testing.T) {
+	data, err := json.Marshal(Lex("", `"open`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `"diagnostics":[{"severity":"error","code":"E0103","message":"unterminated string",` +
+		`"span":{"start":{"offset":0,"line":1,"column":1},"end":{"offset":5,"line":1,"column":6}}}]`
+	if !strings.Contains(string(data), expected) {
+		t.Errorf("expected %s in:\n%s", expected, data)
+	}
+}
+
+func TestTree(t *testing.T) {
+	data, err := json.Marshal(Parse("", "f(-1)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"version":1,"root":` +
+		`{"kind":"Program","span":{"start":{"offset":0,"line":1,"column":1},"end":{"offset":5,"line":1,"column":6}},"children":[` +
+		`{"kind":"ExpressionStatement","field":"statement","span":{"start":{"offset":0,"line":1,"column":1},"end":{"offset":5,"line":1,"column":6}},"children":[` +
+		`{"kind":"CallExpression","field":"expression","span":{"start":{"offset":0,"line":1,"column":1},"end":{"offset":5,"line":1,"column":6}},"children":[` +
+		`{"kind":"Identifier","field":"function","span":{"start":{"offset":0,"line":1,"column":1},"end":{"offset":1,"line":1,"column":2}},"value":"f"},` +
+		`{"kind":"PrefixExpression","field":"argument","span":{"start":{"offset":2,"line":1,"column":3},"end":{"offset":4,"line":1,"column":5}},"value":"-","children":[` +
+		`{"kind":"IntegerLiteral","field":"right","span":{"start":{"offset":3,"line":1,"column":4},"end":{"offset":4,"line":1,"column":5}},"value":"1"}` +
+		`]}]}]}]},"diagnostics":[]}`
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestTreeRoundTrip(t *testing.T) {
+	src := `let add = fn(a, b) { return a + b; };
+if (add(1, 2) != 3) { puts("oops\n") } else { (-1) * !true };
+fn() {}();
+0xff * 1.5e-3 + 100000000000000000000;`
+
+	doc := Parse("round.hai", src)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := DecodeTree(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	program, err := decoded.Root.AST()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(FromAST(program), doc.Root) {
+		again, _ := json.Marshal(FromAST(program))
+		t.Errorf("decoded tree differs:\n%s\n%s", data, again)
+	}
+}
+
+func TestTreeErrors(t *testing.T) {
+	doc := Parse("", "let x = 1; let = 2; let y = ;")
+	if len(doc.Diagnostics) != 2 {
+		t.Errorf("expected 2 diagnostics, got %v", doc.Diagnostics)
+	}
+	var kinds []string
+	for _, c := range doc.Root.Children {
+		kinds = append(kinds, c.Kind)
+	}
+	if strings.Join(kinds, " ") != "LetStatement BadStatement LetStatement" {
+		t.Errorf("unexpected statements %v", kinds)
+	}
+	if value := doc.Root.Children[2].Children[1]; value.Kind != "BadExpression" || value.Span.Start.Column != 29 {
+		t.Errorf("expected a BadExpression at column 29, got %+v", value)
+	}
+	program, err := doc.Root.AST()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(FromAST(program), doc.Root) {
+		t.Errorf("decoded tree with bad nodes differs")
+	}
+
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{`{"version":2,"root":null}`, "unsupported schema version 2"},
+		{`{"version":1,"root":{"kind":"Loop"}}`, `unknown kind of node "Loop"`},
+		{`{"version":1,"root":{"kind":"LetStatement","children":[{"kind":"Identifier","field":"name","value":"x"}]}}`, "missing value"},
+		{`{"version":1,"root":{"kind":"InfixExpression","value":"%"}}`, `invalid infix operator "%"`},
+		{`{"version":1,"root":{"kind":"IntegerLiteral","value":"1.5"}}`, `invalid integer "1.5"`},
+	}
+	for _, tc := range cases {
+		decoded, err := DecodeTree(strings.NewReader(tc.input))
+		if err == nil {
+			_, err = decoded.Root.AST()
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.input, tc.err, err)
+		}
+	}
+}
@@ -1,8 +1,15 @@
 package token
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
 type Token struct {
-	lit string
-	typ TokenType
+	lit  string
+	typ  TokenType
+	span Span
 }
 
 func New[S byte | rune | string](tokenType TokenType, s S) Token {
@@ -13,6 +20,12 @@ func NewIdent(ident string) Token {
 	return Token{typ: IdentType(ident), lit: ident}
 }
 
+// WithSpan returns a copy of the token that covers the given span of source.
+func (t Token) WithSpan(span Span) Token {
+	t.span = span
+	return t
+}
+
 func (t Token) Literal() string {
 	return t.lit
 }
@@ -21,20 +34,94 @@ func (t Token) Type() TokenType {
 	return t.typ
 }
 
+// Span returns the region of source the token was read from.
+func (t Token) Span() Span {
+	return t.span
+}
+
+// Pos returns the position of the first character of the token.
+func (t Token) Pos() Pos {
+	return t.span.Start
+}
+
 func (t Token) Is(typ TokenType) bool {
 	return t.typ == typ
 }
 
+// jsonToken is the JSON form of a Token.
+type jsonToken struct {
+	Type    TokenType `json:"type"`
+	Literal string    `json:"literal"`
+	Span    Span      `json:"span"`
+}
+
+// MarshalJSON encodes the token as an object with its type (in snake case,
+// ie "not_eq"), its literal, and its span.
+func (t Token) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonToken{Type: t.typ, Literal: t.lit, Span: t.span})
+}
+
+func (t *Token) UnmarshalJSON(data []byte) error {
+	var jt jsonToken
+	if err := json.Unmarshal(data, &jt); err != nil {
+		return err
+	}
+	*t = Token{typ: jt.Type, lit: jt.Literal, span: jt.Span}
+	return nil
+}
+
+// Pos is a location in source text.
+// The zero value is not a valid position; lines and columns start at 1.
+type Pos struct {
+	Offset int `json:"offset"` // byte offset into the source, starting at 0
+	Line   int `json:"line"`   // line number, starting at 1
+	Column int `json:"column"` // column number, starting at 1
+}
+
+func (p Pos) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Pos) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Span is a half-open region of source text: Start is the first character
+// covered, and End is the position just past the last character covered.
+type Span struct {
+	File  string `json:"file,omitempty"`
+	Start Pos    `json:"start"`
+	End   Pos    `json:"end"`
+}
+
+// Len returns the number of bytes covered by the span.
+func (s Span) Len() int {
+	return s.End.Offset - s.Start.Offset
+}
+
+func (s Span) String() string {
+	if len(s.File) == 0 {
+		return s.Start.String()
+	}
+	return s.File + ":" + s.Start.String()
+}
+
 //go:generate enumer -type=TokenType -json -transform=snake
 type TokenType uint8
 
 const (
 	ILLEGAL TokenType = iota
 	EOF
+	COMMENT
 
 	// Identifiers + literals
 	IDENT
 	INT
+	FLOAT
+	STRING
 
 	// Operators
 	ASSIGN
@@ -66,22 +153,30 @@ const (
 	RETURN
 )
 
+var keywords = map[string]TokenType{
+	"fn":     FUNCTION,
+	"let":    LET,
+	"true":   TRUE,
+	"false":  FALSE,
+	"if":     IF,
+	"else":   ELSE,
+	"return": RETURN,
+}
+
 func IdentType(ident string) TokenType {
-	switch ident {
-	case "fn":
-		return FUNCTION
-	case "let":
-		return LET
-	case "true":
-		return TRUE
-	case "false":
-		return FALSE
-	case "if":
-		return IF
-	case "else":
-		return ELSE
-	case "return":
-		return RETURN
+	if typ, ok := keywords[ident]; ok {
+		return typ
 	}
 	return IDENT
 }
+
+// Keywords returns all the identifiers that IdentType treats as keywords, in
+// sorted order.
+func Keywords() []string {
+	kws := make([]string, 0, len(keywords))
+	for kw := range keywords {
+		kws = append(kws, kw)
+	}
+	sort.Strings(kws)
+	return kws
+}
@@ -8,11 +8,11 @@ import (
 	"strings"
 )
 
-const _TokenTypeName = "illegaleofidentintassignplusminusbangasteriskslashltgteqnot_eqcommasemicolonlparenrparenlbracerbracefunctionlettruefalseifelsereturn"
+const _TokenTypeName = "illegaleofcommentidentintfloatstringassignplusminusbangasteriskslashltgteqnot_eqcommasemicolonlparenrparenlbracerbracefunctionlettruefalseifelsereturn"
 
-var _TokenTypeIndex = [...]uint8{0, 7, 10, 15, 18, 24, 28, 33, 37, 45, 50, 52, 54, 56, 62, 67, 76, 82, 88, 94, 100, 108, 111, 115, 120, 122, 126, 132}
+var _TokenTypeIndex = [...]uint8{0, 7, 10, 17, 22, 25, 30, 36, 42, 46, 51, 55, 63, 68, 70, 72, 74, 80, 85, 94, 100, 106, 112, 118, 126, 129, 133, 138, 140, 144, 150}
 
-const _TokenTypeLowerName = "illegaleofidentintassignplusminusbangasteriskslashltgteqnot_eqcommasemicolonlparenrparenlbracerbracefunctionlettruefalseifelsereturn"
+const _TokenTypeLowerName = "illegaleofcommentidentintfloatstringassignplusminusbangasteriskslashltgteqnot_eqcommasemicolonlparenrparenlbracerbracefunctionlettruefalseifelsereturn"
 
 func (i TokenType) String() string {
 	if i >= TokenType(len(_TokenTypeIndex)-1) {
@@ -27,120 +27,132 @@ func _TokenTypeNoOp() {
 	var x [1]struct{}
 	_ = x[ILLEGAL-(0)]
 	_ = x[EOF-(1)]
-	_ = x[IDENT-(2)]
-	_ = x[INT-(3)]
-	_ = x[ASSIGN-(4)]
-	_ = x[PLUS-(5)]
-	_ = x[MINUS-(6)]
-	_ = x[BANG-(7)]
-	_ = x[ASTERISK-(8)]
-	_ = x[SLASH-(9)]
-	_ = x[LT-(10)]
-	_ = x[GT-(11)]
-	_ = x[EQ-(12)]
-	_ = x[NOT_EQ-(13)]
-	_ = x[COMMA-(14)]
-	_ = x[SEMICOLON-(15)]
-	_ = x[LPAREN-(16)]
-	_ = x[RPAREN-(17)]
-	_ = x[LBRACE-(18)]
-	_ = x[RBRACE-(19)]
-	_ = x[FUNCTION-(20)]
-	_ = x[LET-(21)]
-	_ = x[TRUE-(22)]
-	_ = x[FALSE-(23)]
-	_ = x[IF-(24)]
-	_ = x[ELSE-(25)]
-	_ = x[RETURN-(26)]
+	_ = x[COMMENT-(2)]
+	_ = x[IDENT-(3)]
+	_ = x[INT-(4)]
+	_ = x[FLOAT-(5)]
+	_ = x[STRING-(6)]
+	_ = x[ASSIGN-(7)]
+	_ = x[PLUS-(8)]
+	_ = x[MINUS-(9)]
+	_ = x[BANG-(10)]
+	_ = x[ASTERISK-(11)]
+	_ = x[SLASH-(12)]
+	_ = x[LT-(13)]
+	_ = x[GT-(14)]
+	_ = x[EQ-(15)]
+	_ = x[NOT_EQ-(16)]
+	_ = x[COMMA-(17)]
+	_ = x[SEMICOLON-(18)]
+	_ = x[LPAREN-(19)]
+	_ = x[RPAREN-(20)]
+	_ = x[LBRACE-(21)]
+	_ = x[RBRACE-(22)]
+	_ = x[FUNCTION-(23)]
+	_ = x[LET-(24)]
+	_ = x[TRUE-(25)]
+	_ = x[FALSE-(26)]
+	_ = x[IF-(27)]
+	_ = x[ELSE-(28)]
+	_ = x[RETURN-(29)]
 }
 
-var _TokenTypeValues = []TokenType{ILLEGAL, EOF, IDENT, INT, ASSIGN, PLUS, MINUS, BANG, ASTERISK, SLASH, LT, GT, EQ, NOT_EQ, COMMA, SEMICOLON, LPAREN, RPAREN, LBRACE, RBRACE, FUNCTION, LET, TRUE, FALSE, IF, ELSE, RETURN}
+var _TokenTypeValues = []TokenType{ILLEGAL, EOF, COMMENT, IDENT, INT, FLOAT, STRING, ASSIGN, PLUS, MINUS, BANG, ASTERISK, SLASH, LT, GT, EQ, NOT_EQ, COMMA, SEMICOLON, LPAREN, RPAREN, LBRACE, RBRACE, FUNCTION, LET, TRUE, FALSE, IF, ELSE, RETURN}
 
 var _TokenTypeNameToValueMap = map[string]TokenType{
 	_TokenTypeName[0:7]:          ILLEGAL,
 	_TokenTypeLowerName[0:7]:     ILLEGAL,
 	_TokenTypeName[7:10]:         EOF,
 	_TokenTypeLowerName[7:10]:    EOF,
-	_TokenTypeName[10:15]:        IDENT,
-	_TokenTypeLowerName[10:15]:   IDENT,
-	_TokenTypeName[15:18]:        INT,
-	_TokenTypeLowerName[15:18]:   INT,
-	_TokenTypeName[18:24]:        ASSIGN,
-	_TokenTypeLowerName[18:24]:   ASSIGN,
-	_TokenTypeName[24:28]:        PLUS,
-	_TokenTypeLowerName[24:28]:   PLUS,
-	_TokenTypeName[28:33]:        MINUS,
-	_TokenTypeLowerName[28:33]:   MINUS,
-	_TokenTypeName[33:37]:        BANG,
-	_TokenTypeLowerName[33:37]:   BANG,
-	_TokenTypeName[37:45]:        ASTERISK,
-	_TokenTypeLowerName[37:45]:   ASTERISK,
-	_TokenTypeName[45:50]:        SLASH,
-	_TokenTypeLowerName[45:50]:   SLASH,
-	_TokenTypeName[50:52]:        LT,
-	_TokenTypeLowerName[50:52]:   LT,
-	_TokenTypeName[52:54]:        GT,
-	_TokenTypeLowerName[52:54]:   GT,
-	_TokenTypeName[54:56]:        EQ,
-	_TokenTypeLowerName[54:56]:   EQ,
-	_TokenTypeName[56:62]:        NOT_EQ,
-	_TokenTypeLowerName[56:62]:   NOT_EQ,
-	_TokenTypeName[62:67]:        COMMA,
-	_TokenTypeLowerName[62:67]:   COMMA,
-	_TokenTypeName[67:76]:        SEMICOLON,
-	_TokenTypeLowerName[67:76]:   SEMICOLON,
-	_TokenTypeName[76:82]:        LPAREN,
-	_TokenTypeLowerName[76:82]:   LPAREN,
-	_TokenTypeName[82:88]:        RPAREN,
-	_TokenTypeLowerName[82:88]:   RPAREN,
-	_TokenTypeName[88:94]:        LBRACE,
-	_TokenTypeLowerName[88:94]:   LBRACE,
-	_TokenTypeName[94:100]:       RBRACE,
-	_TokenTypeLowerName[94:100]:  RBRACE,
-	_TokenTypeName[100:108]:      FUNCTION,
-	_TokenTypeLowerName[100:108]: FUNCTION,
-	_TokenTypeName[108:111]:      LET,
-	_TokenTypeLowerName[108:111]: LET,
-	_TokenTypeName[111:115]:      TRUE,
-	_TokenTypeLowerName[111:115]: TRUE,
-	_TokenTypeName[115:120]:      FALSE,
-	_TokenTypeLowerName[115:120]: FALSE,
-	_TokenTypeName[120:122]:      IF,
-	_TokenTypeLowerName[120:122]: IF,
-	_TokenTypeName[122:126]:      ELSE,
-	_TokenTypeLowerName[122:126]: ELSE,
-	_TokenTypeName[126:132]:      RETURN,
-	_TokenTypeLowerName[126:132]: RETURN,
+	_TokenTypeName[10:17]:        COMMENT,
+	_TokenTypeLowerName[10:17]:   COMMENT,
+	_TokenTypeName[17:22]:        IDENT,
+	_TokenTypeLowerName[17:22]:   IDENT,
+	_TokenTypeName[22:25]:        INT,
+	_TokenTypeLowerName[22:25]:   INT,
+	_TokenTypeName[25:30]:        FLOAT,
+	_TokenTypeLowerName[25:30]:   FLOAT,
+	_TokenTypeName[30:36]:        STRING,
+	_TokenTypeLowerName[30:36]:   STRING,
+	_TokenTypeName[36:42]:        ASSIGN,
+	_TokenTypeLowerName[36:42]:   ASSIGN,
+	_TokenTypeName[42:46]:        PLUS,
+	_TokenTypeLowerName[42:46]:   PLUS,
+	_TokenTypeName[46:51]:        MINUS,
+	_TokenTypeLowerName[46:51]:   MINUS,
+	_TokenTypeName[51:55]:        BANG,
+	_TokenTypeLowerName[51:55]:   BANG,
+	_TokenTypeName[55:63]:        ASTERISK,
+	_TokenTypeLowerName[55:63]:   ASTERISK,
+	_TokenTypeName[63:68]:        SLASH,
+	_TokenTypeLowerName[63:68]:   SLASH,
+	_TokenTypeName[68:70]:        LT,
+	_TokenTypeLowerName[68:70]:   LT,
+	_TokenTypeName[70:72]:        GT,
+	_TokenTypeLowerName[70:72]:   GT,
+	_TokenTypeName[72:74]:        EQ,
+	_TokenTypeLowerName[72:74]:   EQ,
+	_TokenTypeName[74:80]:        NOT_EQ,
+	_TokenTypeLowerName[74:80]:   NOT_EQ,
+	_TokenTypeName[80:85]:        COMMA,
+	_TokenTypeLowerName[80:85]:   COMMA,
+	_TokenTypeName[85:94]:        SEMICOLON,
+	_TokenTypeLowerName[85:94]:   SEMICOLON,
+	_TokenTypeName[94:100]:       LPAREN,
+	_TokenTypeLowerName[94:100]:  LPAREN,
+	_TokenTypeName[100:106]:      RPAREN,
+	_TokenTypeLowerName[100:106]: RPAREN,
+	_TokenTypeName[106:112]:      LBRACE,
+	_TokenTypeLowerName[106:112]: LBRACE,
+	_TokenTypeName[112:118]:      RBRACE,
+	_TokenTypeLowerName[112:118]: RBRACE,
+	_TokenTypeName[118:126]:      FUNCTION,
+	_TokenTypeLowerName[118:126]: FUNCTION,
+	_TokenTypeName[126:129]:      LET,
+	_TokenTypeLowerName[126:129]: LET,
+	_TokenTypeName[129:133]:      TRUE,
+	_TokenTypeLowerName[129:133]: TRUE,
+	_TokenTypeName[133:138]:      FALSE,
+	_TokenTypeLowerName[133:138]: FALSE,
+	_TokenTypeName[138:140]:      IF,
+	_TokenTypeLowerName[138:140]: IF,
+	_TokenTypeName[140:144]:      ELSE,
+	_TokenTypeLowerName[140:144]: ELSE,
+	_TokenTypeName[144:150]:      RETURN,
+	_TokenTypeLowerName[144:150]: RETURN,
 }
 
 var _TokenTypeNames = []string{
 	_TokenTypeName[0:7],
 	_TokenTypeName[7:10],
-	_TokenTypeName[10:15],
-	_TokenTypeName[15:18],
-	_TokenTypeName[18:24],
-	_TokenTypeName[24:28],
-	_TokenTypeName[28:33],
-	_TokenTypeName[33:37],
-	_TokenTypeName[37:45],
-	_TokenTypeName[45:50],
-	_TokenTypeName[50:52],
-	_TokenTypeName[52:54],
-	_TokenTypeName[54:56],
-	_TokenTypeName[56:62],
-	_TokenTypeName[62:67],
-	_TokenTypeName[67:76],
-	_TokenTypeName[76:82],
-	_TokenTypeName[82:88],
-	_TokenTypeName[88:94],
+	_TokenTypeName[10:17],
+	_TokenTypeName[17:22],
+	_TokenTypeName[22:25],
+	_TokenTypeName[25:30],
+	_TokenTypeName[30:36],
+	_TokenTypeName[36:42],
+	_TokenTypeName[42:46],
+	_TokenTypeName[46:51],
+	_TokenTypeName[51:55],
+	_TokenTypeName[55:63],
+	_TokenTypeName[63:68],
+	_TokenTypeName[68:70],
+	_TokenTypeName[70:72],
+	_TokenTypeName[72:74],
+	_TokenTypeName[74:80],
+	_TokenTypeName[80:85],
+	_TokenTypeName[85:94],
 	_TokenTypeName[94:100],
-	_TokenTypeName[100:108],
-	_TokenTypeName[108:111],
-	_TokenTypeName[111:115],
-	_TokenTypeName[115:120],
-	_TokenTypeName[120:122],
-	_TokenTypeName[122:126],
-	_TokenTypeName[126:132],
+	_TokenTypeName[100:106],
+	_TokenTypeName[106:112],
+	_TokenTypeName[112:118],
+	_TokenTypeName[118:126],
+	_TokenTypeName[126:129],
+	_TokenTypeName[129:133],
+	_TokenTypeName[133:138],
+	_TokenTypeName[138:140],
+	_TokenTypeName[140:144],
+	_TokenTypeName[144:150],
 }
 
 // TokenTypeString retrieves an enum value from the enum constants string name.
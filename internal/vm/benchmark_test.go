@@ -0,0 +1,49 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/compiler"
+	"github.com/danbrakeley/hai/internal/evaluator"
+	"github.com/danbrakeley/hai/internal/object"
+)
+
+const fibonacciInput = `
+let fibonacci = fn(x) {
+  if (x < 2) { return x; }
+  fibonacci(x - 1) + fibonacci(x - 2);
+};
+fibonacci(20);`
+
+// BenchmarkFibonacci compares running the same program on the vm and by
+// walking the ast directly. Compile time is included in the vm's numbers.
+func BenchmarkFibonacci(b *testing.B) {
+	program := parse(b, fibonacciInput)
+
+	b.Run("vm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			comp := compiler.New()
+			if err := comp.Compile(program); err != nil {
+				b.Fatalf("compiler error: %s", err)
+			}
+			machine := New(comp.Bytecode())
+			if err := machine.Run(); err != nil {
+				b.Fatalf("vm error: %s", err)
+			}
+			checkFibonacciResult(b, machine.LastPoppedStackElem())
+		}
+	})
+
+	b.Run("eval", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			result := evaluator.Eval(program, object.NewEnvironment())
+			checkFibonacciResult(b, result)
+		}
+	})
+}
+
+func checkFibonacciResult(b *testing.B, obj object.Object) {
+	if i, ok := obj.(*object.Integer); !ok || i.Value != 6765 {
+		b.Fatalf("expected 6765, got %+v", obj)
+	}
+}
@@ -0,0 +1,21 @@
+package vm
+
+import (
+	"github.com/danbrakeley/hai/internal/code"
+	"github.com/danbrakeley/hai/internal/object"
+)
+
+// Frame is the state of a single function call.
+type Frame struct {
+	cl          *object.Closure
+	ip          int // index of the instruction being executed
+	basePointer int // stack pointer before the call; locals start here
+}
+
+func NewFrame(cl *object.Closure, basePointer int) *Frame {
+	return &Frame{cl: cl, ip: -1, basePointer: basePointer}
+}
+
+func (f *Frame) Instructions() code.Instructions {
+	return f.cl.Fn.Instructions
+}
@@ -0,0 +1,577 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/danbrakeley/hai/internal/code"
+	"github.com/danbrakeley/hai/internal/compiler"
+	"github.com/danbrakeley/hai/internal/object"
+)
+
+const (
+	StackSize   = 2048
+	GlobalsSize = 65536
+	MaxFrames   = 1024
+)
+
+// there only ever needs to be one of each of these
+var (
+	True  = &object.Boolean{Value: true}
+	False = &object.Boolean{Value: false}
+	Null  = &object.Null{}
+)
+
+type VM struct {
+	constants []object.Object
+
+	stack []object.Object
+	sp    int // always points to the next free slot; top of stack is stack[sp-1]
+
+	globals  []object.Object
+	builtins []*object.Builtin
+
+	frames      []*Frame
+	framesIndex int
+
+	returned bool // if the program ended with a top-level return
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	return NewWithGlobalsStore(bytecode, make([]object.Object, GlobalsSize))
+}
+
+// NewWithGlobalsStore creates a vm that reads and writes globals in s, so
+// that globals can outlive a single run.
+func NewWithGlobalsStore(bytecode *compiler.Bytecode, s []object.Object) *VM {
+	mainFn := &object.CompiledFunction{Instructions: bytecode.Instructions}
+	mainClosure := &object.Closure{Fn: mainFn}
+	mainFrame := NewFrame(mainClosure, 0)
+
+	frames := make([]*Frame, MaxFrames)
+	frames[0] = mainFrame
+
+	return &VM{
+		constants:   bytecode.Constants,
+		stack:       make([]object.Object, StackSize),
+		sp:          0,
+		globals:     s,
+		builtins:    object.Builtins,
+		frames:      frames,
+		framesIndex: 1,
+	}
+}
+
+// SetOutput sets where the puts builtin writes to, which is os.Stdout by
+// default.
+func (vm *VM) SetOutput(w io.Writer) {
+	vm.builtins = object.NewBuiltins(w)
+}
+
+func (vm *VM) currentFrame() *Frame {
+	return vm.frames[vm.framesIndex-1]
+}
+
+func (vm *VM) pushFrame(f *Frame) error {
+	if vm.framesIndex >= len(vm.frames) {
+		return fmt.Errorf("stack overflow")
+	}
+	vm.frames[vm.framesIndex] = f
+	vm.framesIndex++
+	return nil
+}
+
+func (vm *VM) popFrame() *Frame {
+	vm.framesIndex--
+	return vm.frames[vm.framesIndex]
+}
+
+// LastPoppedStackElem returns the value of the last expression statement
+// executed, which is the result of the program.
+func (vm *VM) LastPoppedStackElem() object.Object {
+	return vm.stack[vm.sp]
+}
+
+// Returned reports if the program was ended by a return statement at the top
+// level, in which case LastPoppedStackElem is the value it returned.
+func (vm *VM) Returned() bool {
+	return vm.returned
+}
+
+func (vm *VM) Run() error {
+	var ip int
+	var ins code.Instructions
+	var op code.Opcode
+
+	for vm.currentFrame().ip < len(vm.currentFrame().Instructions())-1 {
+		vm.currentFrame().ip++
+
+		ip = vm.currentFrame().ip
+		ins = vm.currentFrame().Instructions()
+		op = code.Opcode(ins[ip])
+
+		switch op {
+		case code.OpConstant:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			if err := vm.push(vm.constants[constIndex]); err != nil {
+				return err
+			}
+
+		case code.OpPop:
+			vm.pop()
+
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv:
+			if err := vm.executeBinaryOperation(op); err != nil {
+				return err
+			}
+
+		case code.OpTrue:
+			if err := vm.push(True); err != nil {
+				return err
+			}
+
+		case code.OpFalse:
+			if err := vm.push(False); err != nil {
+				return err
+			}
+
+		case code.OpNull:
+			if err := vm.push(Null); err != nil {
+				return err
+			}
+
+		case code.OpEqual, code.OpNotEqual, code.OpGreaterThan, code.OpLessThan:
+			if err := vm.executeComparison(op); err != nil {
+				return err
+			}
+
+		case code.OpBang:
+			operand := vm.pop()
+			if err := vm.push(nativeBoolToBooleanObject(!isTruthy(operand))); err != nil {
+				return err
+			}
+
+		case code.OpMinus:
+			if err := vm.executeMinusOperator(); err != nil {
+				return err
+			}
+
+		case code.OpJump:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			// the loop increments ip before reading the next instruction
+			vm.currentFrame().ip = pos - 1
+
+		case code.OpJumpNotTruthy:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			condition := vm.pop()
+			if !isTruthy(condition) {
+				vm.currentFrame().ip = pos - 1
+			}
+
+		case code.OpSetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			vm.globals[globalIndex] = vm.pop()
+
+		case code.OpGetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+
+			global := vm.globals[globalIndex]
+			if global == nil {
+				// the statement that defined it failed before setting it
+				return fmt.Errorf("identifier used before it was defined")
+			}
+			if err := vm.push(global); err != nil {
+				return err
+			}
+
+		case code.OpSetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			vm.stack[frame.basePointer+int(localIndex)] = vm.pop()
+
+		case code.OpGetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			if err := vm.push(vm.stack[frame.basePointer+int(localIndex)]); err != nil {
+				return err
+			}
+
+		case code.OpGetFree:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			currentClosure := vm.currentFrame().cl
+			if err := vm.push(currentClosure.Free[freeIndex]); err != nil {
+				return err
+			}
+
+		case code.OpGetBuiltin:
+			builtinIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			if err := vm.push(vm.builtins[builtinIndex]); err != nil {
+				return err
+			}
+
+		case code.OpCall:
+			numArgs := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			if err := vm.executeCall(int(numArgs)); err != nil {
+				return err
+			}
+
+		case code.OpReturnValue:
+			returnValue := vm.pop()
+
+			if vm.framesIndex == 1 {
+				// returning from the top level ends the program, with the
+				// returned value left where LastPoppedStackElem will find it
+				vm.returned = true
+				return nil
+			}
+
+			frame := vm.popFrame()
+			vm.sp = frame.basePointer - 1
+
+			if err := vm.push(returnValue); err != nil {
+				return err
+			}
+
+		case code.OpReturn:
+			frame := vm.popFrame()
+			vm.sp = frame.basePointer - 1
+
+			if err := vm.push(Null); err != nil {
+				return err
+			}
+
+		case code.OpClosure:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			numFree := code.ReadUint8(ins[ip+3:])
+			vm.currentFrame().ip += 3
+
+			if err := vm.pushClosure(int(constIndex), int(numFree)); err != nil {
+				return err
+			}
+
+		case code.OpCurrentClosure:
+			currentClosure := vm.currentFrame().cl
+			if err := vm.push(currentClosure); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("unhandled opcode: %s", op)
+		}
+	}
+
+	return nil
+}
+
+func (vm *VM) push(o object.Object) error {
+	if vm.sp >= StackSize {
+		return fmt.Errorf("stack overflow")
+	}
+
+	vm.stack[vm.sp] = o
+	vm.sp++
+
+	return nil
+}
+
+func (vm *VM) pop() object.Object {
+	o := vm.stack[vm.sp-1]
+	vm.sp--
+	return o
+}
+
+func (vm *VM) executeBinaryOperation(op code.Opcode) error {
+	right := vm.pop()
+	left := vm.pop()
+
+	leftType := left.Type()
+	rightType := right.Type()
+
+	switch {
+	case leftType == object.INTEGER && rightType == object.INTEGER:
+		return vm.executeBinaryIntegerOperation(op, left, right)
+	case isNumber(left) && isNumber(right):
+		// at least one is a float, so both are treated as floats
+		return vm.executeBinaryFloatOperation(op, left, right)
+	case leftType == object.STRING && rightType == object.STRING && op == code.OpAdd:
+		leftValue := left.(*object.String).Value
+		rightValue := right.(*object.String).Value
+		return vm.push(&object.String{Value: leftValue + rightValue})
+	case leftType != rightType:
+		return fmt.Errorf("type mismatch: %s %s %s", leftType, operatorSymbols[op], rightType)
+	default:
+		return fmt.Errorf("unknown operator: %s %s %s", leftType, operatorSymbols[op], rightType)
+	}
+}
+
+func (vm *VM) executeBinaryIntegerOperation(op code.Opcode, left, right object.Object) error {
+	leftValue := left.(*object.Integer)
+	rightValue := right.(*object.Integer)
+
+	var result *object.Integer
+
+	switch op {
+	case code.OpAdd:
+		result = leftValue.Add(rightValue)
+	case code.OpSub:
+		result = leftValue.Sub(rightValue)
+	case code.OpMul:
+		result = leftValue.Mul(rightValue)
+	case code.OpDiv:
+		if rightValue.Sign() == 0 {
+			return fmt.Errorf("division by zero")
+		}
+		result = leftValue.Quo(rightValue)
+	default:
+		return fmt.Errorf("unknown integer operator: %s", op)
+	}
+
+	return vm.push(result)
+}
+
+func (vm *VM) executeBinaryFloatOperation(op code.Opcode, left, right object.Object) error {
+	leftValue, _ := object.AsFloat(left)
+	rightValue, _ := object.AsFloat(right)
+
+	var result float64
+
+	switch op {
+	case code.OpAdd:
+		result = leftValue + rightValue
+	case code.OpSub:
+		result = leftValue - rightValue
+	case code.OpMul:
+		result = leftValue * rightValue
+	case code.OpDiv:
+		if rightValue == 0 {
+			return fmt.Errorf("division by zero")
+		}
+		result = leftValue / rightValue
+	default:
+		return fmt.Errorf("unknown float operator: %s", op)
+	}
+
+	return vm.push(&object.Float{Value: result})
+}
+
+func (vm *VM) executeComparison(op code.Opcode) error {
+	right := vm.pop()
+	left := vm.pop()
+
+	leftType := left.Type()
+	rightType := right.Type()
+
+	switch {
+	case leftType == object.INTEGER && rightType == object.INTEGER:
+		return vm.executeIntegerComparison(op, left, right)
+	case isNumber(left) && isNumber(right):
+		return vm.executeFloatComparison(op, left, right)
+	case leftType == object.STRING && rightType == object.STRING:
+		return vm.executeStringComparison(op, left, right)
+	case leftType != rightType:
+		return fmt.Errorf("type mismatch: %s %s %s", leftType, operatorSymbols[op], rightType)
+	}
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(objectsEqual(left, right)))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(!objectsEqual(left, right)))
+	default:
+		return fmt.Errorf("unknown operator: %s %s %s", leftType, operatorSymbols[op], rightType)
+	}
+}
+
+func (vm *VM) executeIntegerComparison(op code.Opcode, left, right object.Object) error {
+	cmp := left.(*object.Integer).Cmp(right.(*object.Integer))
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp == 0))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(cmp != 0))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(cmp > 0))
+	case code.OpLessThan:
+		return vm.push(nativeBoolToBooleanObject(cmp < 0))
+	default:
+		return fmt.Errorf("unknown operator: %s", op)
+	}
+}
+
+func (vm *VM) executeFloatComparison(op code.Opcode, left, right object.Object) error {
+	leftValue, _ := object.AsFloat(left)
+	rightValue, _ := object.AsFloat(right)
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(leftValue == rightValue))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(leftValue != rightValue))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(leftValue > rightValue))
+	case code.OpLessThan:
+		return vm.push(nativeBoolToBooleanObject(leftValue < rightValue))
+	default:
+		return fmt.Errorf("unknown operator: %s", op)
+	}
+}
+
+func (vm *VM) executeStringComparison(op code.Opcode, left, right object.Object) error {
+	leftValue := left.(*object.String).Value
+	rightValue := right.(*object.String).Value
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(leftValue == rightValue))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(leftValue != rightValue))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(leftValue > rightValue))
+	case code.OpLessThan:
+		return vm.push(nativeBoolToBooleanObject(leftValue < rightValue))
+	default:
+		return fmt.Errorf("unknown operator: %s", op)
+	}
+}
+
+func (vm *VM) executeMinusOperator() error {
+	operand := vm.pop()
+
+	switch operand := operand.(type) {
+	case *object.Integer:
+		return vm.push(operand.Neg())
+	case *object.Float:
+		return vm.push(&object.Float{Value: -operand.Value})
+	default:
+		return fmt.Errorf("unknown operator: -%s", operand.Type())
+	}
+}
+
+func (vm *VM) executeCall(numArgs int) error {
+	callee := vm.stack[vm.sp-1-numArgs]
+	switch callee := callee.(type) {
+	case *object.Closure:
+		return vm.callClosure(callee, numArgs)
+	case *object.Builtin:
+		return vm.callBuiltin(callee, numArgs)
+	default:
+		return fmt.Errorf("not a function: %s", callee.Type())
+	}
+}
+
+func (vm *VM) callBuiltin(builtin *object.Builtin, numArgs int) error {
+	args := vm.stack[vm.sp-numArgs : vm.sp]
+
+	result := builtin.Fn(args...)
+	vm.sp = vm.sp - numArgs - 1
+
+	switch result := result.(type) {
+	case nil:
+		return vm.push(Null)
+	case *object.Error:
+		return fmt.Errorf("%s", result.Message)
+	default:
+		return vm.push(result)
+	}
+}
+
+func (vm *VM) callClosure(cl *object.Closure, numArgs int) error {
+	if numArgs != cl.Fn.NumParameters {
+		return fmt.Errorf("wrong number of arguments: want=%d, got=%d", cl.Fn.NumParameters, numArgs)
+	}
+
+	frame := NewFrame(cl, vm.sp-numArgs)
+	if err := vm.pushFrame(frame); err != nil {
+		return err
+	}
+
+	// reserve space on the stack for the function's locals
+	vm.sp = frame.basePointer + cl.Fn.NumLocals
+	if vm.sp >= StackSize {
+		return fmt.Errorf("stack overflow")
+	}
+
+	return nil
+}
+
+func (vm *VM) pushClosure(constIndex int, numFree int) error {
+	constant := vm.constants[constIndex]
+	function, ok := constant.(*object.CompiledFunction)
+	if !ok {
+		return fmt.Errorf("not a function: %+v", constant)
+	}
+
+	free := make([]object.Object, numFree)
+	copy(free, vm.stack[vm.sp-numFree:vm.sp])
+	vm.sp = vm.sp - numFree
+
+	closure := &object.Closure{Fn: function, Free: free}
+	return vm.push(closure)
+}
+
+// operatorSymbols maps opcodes back to the source operator, for error messages
+var operatorSymbols = map[code.Opcode]string{
+	code.OpAdd:         "+",
+	code.OpSub:         "-",
+	code.OpMul:         "*",
+	code.OpDiv:         "/",
+	code.OpEqual:       "==",
+	code.OpNotEqual:    "!=",
+	code.OpGreaterThan: ">",
+	code.OpLessThan:    "<",
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return True
+	}
+	return False
+}
+
+func isTruthy(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.Boolean:
+		return obj.Value
+	case *object.Null:
+		return false
+	default:
+		return true
+	}
+}
+
+// isNumber reports if obj is an integer or a float.
+func isNumber(obj object.Object) bool {
+	_, ok := object.AsFloat(obj)
+	return ok
+}
+
+// objectsEqual compares booleans and nulls by value, and everything else by
+// identity
+func objectsEqual(left, right object.Object) bool {
+	switch left := left.(type) {
+	case *object.Boolean:
+		return left.Value == right.(*object.Boolean).Value
+	case *object.Null:
+		return true
+	default:
+		return left == right
+	}
+}
@@ -0,0 +1,307 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/danbrakeley/hai/internal/ast"
+	"github.com/danbrakeley/hai/internal/compiler"
+	"github.com/danbrakeley/hai/internal/lexer"
+	"github.com/danbrakeley/hai/internal/object"
+	"github.com/danbrakeley/hai/internal/parser"
+)
+
+type vmTestCase struct {
+	input    string
+	expected any
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"1", 1},
+		{"1 + 2", 3},
+		{"1 - 2", -1},
+		{"4 / 2", 2},
+		{"50 / 2 * 2 + 10 - 5", 55},
+		{"5 * (2 + 10)", 60},
+		{"-5", -5},
+		{"-50 + 100 + -50", 0},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+	})
+}
+
+func TestNumberLiterals(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"0xff", 255},
+		{"0o17 + 0b101", 20},
+		{"1_000_000", 1000000},
+		{"1.5", 1.5},
+		{"2.5e3", 2500.0},
+	})
+}
+
+func TestBigIntegerArithmetic(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"9223372036854775807 + 1 == 9223372036854775808", true},
+		{"-9223372036854775808 - 1 < -9223372036854775808", true},
+		{"100000000000000000000 / 10000000000", 10000000000},
+		{"100000000000000000000 - 99999999999999999999", 1},
+		{"4294967296 * 4294967296 > 18446744073709551615", true},
+		{"100000000000000000000 * 0.5", 5e19},
+	})
+}
+
+func TestFloatArithmetic(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"1.5 + 2.25", 3.75},
+		{"-1.5", -1.5},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2.0},
+		{"7 / 2", 3},
+		{"7 / 2.0", 3.5},
+		{"7.0 - 2", 5.0},
+		{"1 == 1.0", true},
+		{"1 != 1.5", true},
+		{"1 < 1.5", true},
+		{"2.5 > 3", false},
+	})
+}
+
+func TestBooleanExpressions(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"true", true},
+		{"false", false},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"true == true", true},
+		{"true != false", true},
+		{"(1 < 2) == true", true},
+		{"!true", false},
+		{"!5", false},
+		{"!!5", true},
+		{"!(if (false) { 5; })", true},
+	})
+}
+
+func TestStringExpressions(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{`"hai"`, "hai"},
+		{`"h" + "a" + "i"`, "hai"},
+		{`let greet = fn(name) { "hi " + name }; greet("bob")`, "hi bob"},
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" < "b"`, true},
+		{`"b" > "a"`, true},
+		{`("a" + "b") == "ab"`, true},
+	})
+}
+
+func TestConditionals(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"if (true) { 10 }", 10},
+		{"if (true) { 10 } else { 20 }", 10},
+		{"if (false) { 10 } else { 20 } ", 20},
+		{"if (1 < 2) { 10 }", 10},
+		{"if (1 > 2) { 10 }", Null},
+		{"if (false) { 10 }", Null},
+		{"if (true) { }", Null},
+		{"if (true) { let x = 1; }", Null},
+		{"if ((if (false) { 10 })) { 10 } else { 20 }", 20},
+	})
+}
+
+func TestGlobalLetStatements(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"let one = 1; one", 1},
+		{"let one = 1; let two = 2; one + two", 3},
+		{"let one = 1; let two = one + one; one + two", 3},
+		{"let one = 1; let one = one + 1; one", 2},
+	})
+}
+
+func TestReturnStatements(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"return 10; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
+		{"if (10 > 1) { if (10 > 1) { return 10; } return 1; }", 10},
+	})
+}
+
+func TestCallingFunctions(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"let f = fn() { 5 + 10; }; f();", 15},
+		{"let one = fn() { 1; }; let two = fn() { 2; }; one() + two()", 3},
+		{"let earlyExit = fn() { return 99; 100; }; earlyExit();", 99},
+		{"let noReturn = fn() { }; noReturn();", Null},
+		{"let endsInLet = fn() { let x = 1; }; endsInLet();", Null},
+		{"let identity = fn(a) { a; }; identity(4);", 4},
+		{"let sum = fn(a, b) { let c = a + b; c; }; sum(1, 2) + sum(3, 4);", 10},
+		{"let globalNum = 10; let sum = fn(a, b) { let c = a + b; c + globalNum; }; sum(1, 2);", 13},
+		{"let returnsOne = fn() { 1; }; let returnsOneReturner = fn() { returnsOne; }; returnsOneReturner()();", 1},
+	})
+}
+
+func TestClosures(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"let newClosure = fn(a) { fn() { a; }; }; let closure = newClosure(99); closure();", 99},
+		{"let newAdder = fn(a, b) { fn(c) { a + b + c }; }; let adder = newAdder(1, 2); adder(8);", 11},
+		{`
+let newAdderOuter = fn(a, b) {
+  let c = a + b;
+  fn(d) {
+    let e = d + c;
+    fn(f) { e + f; };
+  };
+};
+let newAdderInner = newAdderOuter(1, 2);
+let adder = newAdderInner(3);
+adder(8);`, 14},
+	})
+}
+
+func TestRecursiveFunctions(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{"let countDown = fn(x) { if (x == 0) { return 0; } else { countDown(x - 1); } }; countDown(1);", 0},
+		{`
+let wrapper = fn() {
+  let countDown = fn(x) { if (x == 0) { return 0; } else { countDown(x - 1); } };
+  countDown(1);
+};
+wrapper();`, 0},
+		{`
+let fibonacci = fn(x) {
+  if (x == 0) { return 0; }
+  if (x == 1) { return 1; }
+  fibonacci(x - 1) + fibonacci(x - 2);
+};
+fibonacci(15);`, 610},
+	})
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	runVmTests(t, []vmTestCase{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len("héllo")`, 5},
+		{`let len = fn(s) { 42 }; len("four")`, 42},
+		{`fn(len) { len }(7)`, 7},
+	})
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"5 + true;", "type mismatch: integer + boolean"},
+		{"5 == true;", "type mismatch: integer == boolean"},
+		{"-true", "unknown operator: -boolean"},
+		{"true + false;", "unknown operator: boolean + boolean"},
+		{"true < false;", "unknown operator: boolean < boolean"},
+		{`"Hello" - "World"`, "unknown operator: string - string"},
+		{`"Hello" + 1`, "type mismatch: string + integer"},
+		{`-"a"`, "unknown operator: -string"},
+		{"10 / 0", "division by zero"},
+		{"10 / 0.0", "division by zero"},
+		{"1.5 + true", "type mismatch: float + boolean"},
+		{"5(1)", "not a function: integer"},
+		{"fn(x) { x }(1, 2)", "wrong number of arguments: want=1, got=2"},
+		{"let f = fn(x) { f(x) }; f(1)", "stack overflow"},
+		{"len(1)", "argument to `len` not supported, got integer"},
+		{`len("one", "two")`, "wrong number of arguments: want=1, got=2"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			comp := compiler.New()
+			if err := comp.Compile(parse(t, tc.input)); err != nil {
+				t.Fatalf("compiler error: %s", err)
+			}
+
+			err := New(comp.Bytecode()).Run()
+			if err == nil {
+				t.Fatalf("expected vm error, got none")
+			}
+			if err.Error() != tc.expected {
+				t.Errorf("expected error '%s', got '%s'", tc.expected, err)
+			}
+		})
+	}
+}
+
+func runVmTests(t *testing.T, cases []vmTestCase) {
+	t.Helper()
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			comp := compiler.New()
+			if err := comp.Compile(parse(t, tc.input)); err != nil {
+				t.Fatalf("compiler error: %s", err)
+			}
+
+			vm := New(comp.Bytecode())
+			if err := vm.Run(); err != nil {
+				t.Fatalf("vm error: %s", err)
+			}
+
+			testExpectedObject(t, tc.expected, vm.LastPoppedStackElem())
+		})
+	}
+}
+
+func parse(t testing.TB, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if diags := p.Diagnostics(); len(diags) > 0 {
+		t.Fatalf("unexpected parse error: %s", diags[0].Error())
+	}
+	return program
+}
+
+func testExpectedObject(t *testing.T, expected any, actual object.Object) {
+	t.Helper()
+
+	switch expected := expected.(type) {
+	case int:
+		result, ok := actual.(*object.Integer)
+		if !ok {
+			t.Fatalf("expected *object.Integer, got %T (%+v)", actual, actual)
+		}
+		if result.Value != int64(expected) {
+			t.Errorf("expected %d, got %d", expected, result.Value)
+		}
+	case float64:
+		result, ok := actual.(*object.Float)
+		if !ok {
+			t.Fatalf("expected *object.Float, got %T (%+v)", actual, actual)
+		}
+		if result.Value != expected {
+			t.Errorf("expected %g, got %g", expected, result.Value)
+		}
+	case bool:
+		result, ok := actual.(*object.Boolean)
+		if !ok {
+			t.Fatalf("expected *object.Boolean, got %T (%+v)", actual, actual)
+		}
+		if result.Value != expected {
+			t.Errorf("expected %t, got %t", expected, result.Value)
+		}
+	case string:
+		result, ok := actual.(*object.String)
+		if !ok {
+			t.Fatalf("expected *object.String, got %T (%+v)", actual, actual)
+		}
+		if result.Value != expected {
+			t.Errorf("expected %q, got %q", expected, result.Value)
+		}
+	case *object.Null:
+		if actual != Null {
+			t.Errorf("expected Null, got %T (%+v)", actual, actual)
+		}
+	default:
+		t.Fatalf("unhandled expected type %T", expected)
+	}
+}
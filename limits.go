@@ -0,0 +1,64 @@
+package hai
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/danbrakeley/hai/internal/evaluator"
+)
+
+// Limits bounds the resources that each Run may use, so that programs from
+// untrusted sources cannot hang or exhaust the process running them. A limit
+// of zero means there is no limit, though calls can never nest more than
+// 10000 deep; going deeper is a "stack overflow" runtime error.
+//
+// Limits are checked as a program runs, not while it is in a function
+// registered from Go.
+type Limits struct {
+	Steps   int64         // expressions and statements evaluated
+	Depth   int64         // nested calls of Hai functions
+	Objects int64         // values allocated
+	Bytes   int64         // approximate size of the values allocated
+	Time    time.Duration // wall time
+}
+
+// Limit names one of the Limits.
+type Limit string
+
+const (
+	StepLimit   Limit = "step"
+	DepthLimit  Limit = "call depth"
+	ObjectLimit Limit = "object"
+	ByteLimit   Limit = "byte"
+	TimeLimit   Limit = "time"
+)
+
+// LimitExceeded is returned by Run when a program is stopped for going over
+// one of its Limits.
+type LimitExceeded struct {
+	Limit Limit
+	Max   int64 // the value of the limit; for TimeLimit, a time.Duration
+}
+
+func (e *LimitExceeded) Error() string {
+	if e.Limit == TimeLimit {
+		return fmt.Sprintf("hai: exceeded the %s limit of %s", e.Limit, time.Duration(e.Max))
+	}
+	return fmt.Sprintf("hai: exceeded the %s limit of %d", e.Limit, e.Max)
+}
+
+// WithLimits sets the limits on each Run.
+func WithLimits(limits Limits) Option {
+	return func(in *Interpreter) {
+		in.limits = limits
+	}
+}
+
+func (l Limits) evaluator() evaluator.Limits {
+	return evaluator.Limits{
+		Steps:   l.Steps,
+		Depth:   l.Depth,
+		Objects: l.Objects,
+		Bytes:   l.Bytes,
+	}
+}
@@ -0,0 +1,93 @@
+package hai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimits(t *testing.T) {
+	const recurse = "let f = fn() { f() }; f();"
+
+	cases := []struct {
+		limits   Limits
+		expected string
+	}{
+		{Limits{Depth: 100}, "hai: exceeded the call depth limit of 100"},
+		{Limits{Steps: 1000}, "hai: exceeded the step limit of 1000"},
+		{Limits{Objects: 1000}, "hai: exceeded the object limit of 1000"},
+		{Limits{Bytes: 10000}, "hai: exceeded the byte limit of 10000"},
+	}
+	for _, tc := range cases {
+		_, err := New(WithLimits(tc.limits)).Eval(recurse)
+		var limitErr *LimitExceeded
+		if !errors.As(err, &limitErr) {
+			t.Errorf("%+v: expected a *LimitExceeded, got %#v", tc.limits, err)
+			continue
+		}
+		if err.Error() != tc.expected {
+			t.Errorf("%+v: expected %q, got %q", tc.limits, tc.expected, err.Error())
+		}
+	}
+}
+
+func TestUnboundedRecursion(t *testing.T) {
+	_, err := New().Eval("let f = fn() { f() }; f();")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) || runtimeErr.Message != "stack overflow" {
+		t.Fatalf("expected a stack overflow runtime error, got %#v", err)
+	}
+}
+
+func TestDeeplyNestedExpression(t *testing.T) {
+	_, err := New().Eval(strings.Repeat("-", 3_000_000) + "1")
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Problems[0].Code != "E0209" {
+		t.Fatalf("expected an E0209 syntax error, got %#v", err)
+	}
+}
+
+func TestLimitsApplyToEachRun(t *testing.T) {
+	in := New(WithLimits(Limits{Steps: 100}))
+	program, err := in.Compile("let a = 1 + 2 + 3;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if _, err := in.Run(context.Background(), program); err != nil {
+			t.Fatalf("run %d: unexpected error: %s", i, err)
+		}
+	}
+}
+
+func TestTimeLimit(t *testing.T) {
+	in := New(WithLimits(Limits{Depth: 100, Time: 10 * time.Millisecond}))
+	if err := in.RegisterFunc("sleep", func() { time.Sleep(time.Millisecond) }); err != nil {
+		t.Fatal(err)
+	}
+	_, err := in.Eval("let f = fn(n) { sleep(); if (n > 0) { f(n - 1) }; f(n) }; f(50)")
+	var limitErr *LimitExceeded
+	if !errors.As(err, &limitErr) || limitErr.Limit != TimeLimit {
+		t.Fatalf("expected the time limit to be exceeded, got %#v", err)
+	}
+	if err.Error() != "hai: exceeded the time limit of 10ms" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := New(WithLimits(Limits{Depth: 100}))
+	if err := in.RegisterFunc("cancel", func() { cancel() }); err != nil {
+		t.Fatal(err)
+	}
+	program, err := in.Compile("let f = fn(n) { if (n > 0) { f(n - 1) }; f(n) }; cancel(); f(50)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Run(ctx, program); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %#v", err)
+	}
+}
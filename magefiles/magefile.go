@@ -18,6 +18,12 @@ func Test() {
 	sh.Cmd("go test ./...").Run()
 }
 
+// Bench runs benchmarks comparing the vm to the tree-walking evaluator
+func Bench() {
+	sh.Echo("Running benchmarks...")
+	sh.Cmd("go test -run ^$ -bench . ./internal/vm").Run()
+}
+
 // Gen runs go generate for all packages
 func Gen() {
 	sh.Echo("Running go generate...")
@@ -42,9 +48,10 @@ func BuildHai() {
 	sh.Cmdf(
 		`go build -ldflags '`+
 			`-X "github.com/danbrakeley/hai/internal/buildvar.Version=%s" `+
+			`-X "github.com/danbrakeley/hai/internal/buildvar.Commit=%s" `+
 			`-X "github.com/danbrakeley/hai/internal/buildvar.BuildTime=%s" `+
 			`-X "github.com/danbrakeley/hai/internal/buildvar.ReleaseURL=https://github.com/danbrakeley/hai"`+
-			`' -o local/%s ./cmd/%s`, commit, time.Now().Format(time.RFC3339), target, hai,
+			`' -o local/%s ./cmd/%s`, commit, commit, time.Now().Format(time.RFC3339), target, hai,
 	).Run()
 }
 
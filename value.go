@@ -0,0 +1,97 @@
+package hai
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/danbrakeley/hai/internal/evaluator"
+	"github.com/danbrakeley/hai/internal/object"
+)
+
+// Object is a value as it is held inside the interpreter. Values that have no
+// Go equivalent, such as functions, are given to Go as Objects, which can be
+// passed back to Hai unchanged. Inspect returns the value as Hai would print
+// it.
+type Object = object.Object
+
+// ToGo converts a Hai value to Go. Integers become int64, or *big.Int if they
+// are too large for an int64, floats become float64, strings become string,
+// booleans become bool, and null becomes nil. Anything else is returned as an
+// Object.
+func ToGo(obj Object) any {
+	switch obj := obj.(type) {
+	case nil, *object.Null:
+		return nil
+	case *object.Integer:
+		if obj.IsBig() {
+			return obj.BigInt()
+		}
+		return obj.Value
+	case *object.Float:
+		return obj.Value
+	case *object.String:
+		return obj.Value
+	case *object.Boolean:
+		return obj.Value
+	default:
+		return obj
+	}
+}
+
+// FromGo converts a Go value to Hai. It accepts nil, bool, string, any
+// integer type, *big.Int, float32 and float64, and Objects (as returned by
+// ToGo).
+func FromGo(v any) (Object, error) {
+	switch v := v.(type) {
+	case nil:
+		return evaluator.NULL, nil
+	case bool:
+		if v {
+			return evaluator.TRUE, nil
+		}
+		return evaluator.FALSE, nil
+	case string:
+		return &object.String{Value: v}, nil
+	case int:
+		return &object.Integer{Value: int64(v)}, nil
+	case int8:
+		return &object.Integer{Value: int64(v)}, nil
+	case int16:
+		return &object.Integer{Value: int64(v)}, nil
+	case int32:
+		return &object.Integer{Value: int64(v)}, nil
+	case int64:
+		return &object.Integer{Value: v}, nil
+	case uint:
+		return fromUint(uint64(v)), nil
+	case uint8:
+		return &object.Integer{Value: int64(v)}, nil
+	case uint16:
+		return &object.Integer{Value: int64(v)}, nil
+	case uint32:
+		return &object.Integer{Value: int64(v)}, nil
+	case uint64:
+		return fromUint(v), nil
+	case *big.Int:
+		if v == nil {
+			return evaluator.NULL, nil
+		}
+		return object.NewBigInteger(new(big.Int).Set(v)), nil
+	case float32:
+		return &object.Float{Value: float64(v)}, nil
+	case float64:
+		return &object.Float{Value: v}, nil
+	case Object:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("hai: cannot convert %T to a Hai value", v)
+	}
+}
+
+func fromUint(v uint64) Object {
+	if v > math.MaxInt64 {
+		return object.NewBigInteger(new(big.Int).SetUint64(v))
+	}
+	return &object.Integer{Value: int64(v)}
+}
@@ -0,0 +1,68 @@
+package hai
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestValueRoundTrip(t *testing.T) {
+	for _, v := range []any{nil, true, false, "", "hai", int64(0), int64(-7), 1.5} {
+		obj, err := FromGo(v)
+		if err != nil {
+			t.Fatalf("FromGo(%#v): %s", v, err)
+		}
+		if actual := ToGo(obj); actual != v {
+			t.Errorf("expected %#v, got %#v", v, actual)
+		}
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	in := New()
+	if err := in.SetGlobal("max", uint64(math.MaxUint64)); err != nil {
+		t.Fatal(err)
+	}
+	result, err := in.Eval("max * max + 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, ok := result.(*big.Int)
+	if !ok {
+		t.Fatalf("expected a *big.Int, got %#v", result)
+	}
+	max := new(big.Int).SetUint64(math.MaxUint64)
+	expected := new(big.Int).Add(new(big.Int).Mul(max, max), big.NewInt(1))
+	if b.Cmp(expected) != 0 {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+
+	// the value given to Go is a copy
+	b.SetInt64(0)
+	obj, err := FromGo(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected.SetInt64(0)
+	if obj.Inspect() != "340282366920938463426481119284349108226" {
+		t.Errorf("unexpected value %s", obj.Inspect())
+	}
+
+	// values that fit in an int64 are given to Go as one
+	if err := in.SetGlobal("big", new(big.Int).Lsh(big.NewInt(1), 100)); err != nil {
+		t.Fatal(err)
+	}
+	expectEval(t, in, "big / big", int64(1))
+}
+
+func TestFromGoIntegers(t *testing.T) {
+	for _, v := range []any{int(3), int8(3), int16(3), int32(3), int64(3), uint(3), uint8(3), uint16(3), uint32(3), uint64(3)} {
+		obj, err := FromGo(v)
+		if err != nil {
+			t.Fatalf("FromGo(%T): %s", v, err)
+		}
+		if actual := ToGo(obj); actual != int64(3) {
+			t.Errorf("FromGo(%T): expected 3, got %#v", v, actual)
+		}
+	}
+}